@@ -2,11 +2,18 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/audio"
 	"github.com/NoetherianRing/Chip-8/chip8"
 	"github.com/NoetherianRing/Chip-8/config"
+	"github.com/NoetherianRing/Chip-8/debugger"
 	"github.com/NoetherianRing/Chip-8/keyhandlers"
 	"github.com/NoetherianRing/Chip-8/monitor"
+	"github.com/NoetherianRing/Chip-8/movie"
+	"github.com/NoetherianRing/Chip-8/recorder"
+	"github.com/NoetherianRing/Chip-8/rewind"
 	"github.com/NoetherianRing/Chip-8/state"
+	"github.com/NoetherianRing/Chip-8/trace"
 	"github.com/faiface/beep"
 	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/speaker"
@@ -15,40 +22,100 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
+//SampleRate is the sample rate of the synthesized audio
+const SampleRate = beep.SampleRate(44100)
+
 type App struct {
 	c8           *chip8.Chip8
 	keypad       keyhandlers.KeyHandler
 	keyboard     keyhandlers.KeyHandler
 	m            monitor.Monitor
 	beepFile     *os.File
-	beepStreamer beep.StreamSeekCloser
+	beepStreamer beep.StreamSeeker      //Beep file played while the chip8 beeps, if the configuration has one
+	buzzer       *audio.Buzzer          //Synthesized beep played when there isn't a beep file
+	pattern      *audio.PatternStreamer //Audio pattern played instead of the beep if the quirks profile is xochip
+	beeping      bool                   //The chip8 beeped in the last frame
 	cfg          config.Config
 	window       *pixelgl.Window
-	channel      chan byte
+	ipf          int        //Instructions executed per frame
+	faults       chan error //The goroutine which cycles the chip8 sends the error that halted it
+	dbg          *debugger.Debugger
+	overlay      bool               //The registers of the chip8 are drawn over the screen in debug mode
+	tasks        chan func()        //Tasks executed by the goroutine which cycles the chip8 between frames, like saving its state
+	startState   string             //Save state loaded by Run after the ROM
+	history      *rewind.Buffer     //States of the last frames, popped while the rewind key is held
+	tracer       *trace.Tracer      //Traces the instructions executed if the configuration has a trace file
+	stopped      chan struct{}      //Closed when the goroutine which cycles the chip8 returns
+	palette      monitor.Palette    //Colours of the display, also used by the screenshots and the recordings
+	recording    *recorder.Recorder //Records the display after every frame while it's not nil
+	recordFile   string             //File of the recording
+	keys         *chip8.KeypadState //Keys held down in the keypad of the chip8 during the frame
+	keyboardKeys *chip8.KeypadState //Keys of the keypad held down in the keyboard, which are set in the keypad of the chip8 every frame
+	movie        *movie.Movie       //Movie which is recorded while it's not nil
+	movieFile    string             //File of the movie which is recorded
+	playback     *movie.Movie       //Movie which is played while it's not nil
+	played       int                //Frames of the movie which have been played
 }
 
 //NewApp instantiates the App in which the chip8 is going to run.
-//It contains a chip8, a configuration, a beepFile and a beepStream to manage the sound,
+//It contains a chip8, a configuration, and a beepStream to manage the sound, read from the beepFile of the configuration,
+//or a buzzer which synthesizes the beep with the audio settings of the configuration if it doesn't have one
+//(or a synthesized stream of the audio pattern if the quirks profile is xochip),
 //a pixelgl window which is used for all the peripherals,
-//and the peripherals: a monitor(m) which draws the FrameBuffer of the chip 8, a keypad  which manages all the inputs of the chip8,
+//and the peripherals: a monitor(m) which draws the FrameBuffer of the chip 8 with the colours of the configuration,
+//a keypad which manages all the inputs of the chip8 with the keys of the configuration, which are set in the keypad of the chip8 at the start of every frame,
 //and a keyboard which manages the inputs of the app (in this case we only use it to quit when we press Esc., a key which is not used by chip8 ROM files).
-//NewApp also load the fonts file given in the configuration.
+//NewApp also load the fonts file given in the configuration, if it has one, instead of the built-in fonts of the chip8.
 func NewApp(cfg config.Config) (*App, error) {
 	myApp := new(App)
 	var err error
 	myApp.cfg = cfg
-	myApp.channel = make(chan byte, 4)
-	myApp.c8, err = chip8.NewChip8(myApp.channel)
+	myApp.faults = make(chan error, 1)
+	myApp.tasks = make(chan func(), NumberOfSlots)
+	myApp.history = rewind.New(cfg.Rewind.Seconds)
+	myApp.stopped = make(chan struct{})
+	myApp.ipf = chip8.DefaultIPF
+	if cfg.Speed.IPF > 0 {
+		myApp.ipf = cfg.Speed.IPF
+	}
+
+	quirks := chip8.Quirks{}
+	if cfg.Quirks.Profile != "" {
+		quirks, err = chip8.QuirksProfile(cfg.Quirks.Profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	random, err := chip8.ParseRandom(cfg.Random.Source, cfg.Random.Seed, cfg.Random.File)
 	if err != nil {
 		return nil, err
 	}
+	myApp.keys, myApp.keyboardKeys = &chip8.KeypadState{}, &chip8.KeypadState{}
+	myApp.c8, err = chip8.NewChip8(myApp.keys, quirks, random)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Trace.File != "" {
+		myApp.tracer, err = trace.Open(trace.Options(cfg.Trace))
+		if err != nil {
+			return nil, err
+		}
+		myApp.tracer.Attach(myApp.c8)
+	}
 
+	scale := cfg.Display.Scale
+	if scale <= 0 {
+		scale = config.DefaultScale
+	}
 	cfgPixel := pixelgl.WindowConfig{
 		Title:       "Chip-8",
-		Bounds:      pixel.R(0, 0, monitor.WidthScreen, monitor.HeightScreen),
+		Bounds:      pixel.R(0, 0, float64(scale*monitor.LowResWidth), float64(scale*monitor.LowResHeight)),
 		VSync:       true,
 		Undecorated: true,
 	}
@@ -58,63 +125,101 @@ func NewApp(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
-	absPathBeep, err := filepath.Abs(cfg.Paths.Beep)
-
-	if err != nil {
-		panic(err)
-	}
-	myApp.m = monitor.NewMonitor(myApp.window)
-	myApp.beepFile, err = os.Open(absPathBeep)
+	myApp.palette, err = monitor.ParsePalette(cfg.Display.Palette, cfg.Display.Colors)
 	if err != nil {
 		return nil, err
 	}
+	myApp.m = monitor.NewMonitor(myApp.window, monitor.Style{
+		Palette:  myApp.palette,
+		Grid:     cfg.Display.Grid,
+		Phosphor: cfg.Display.Phosphor,
+	})
+
+	if strings.EqualFold(cfg.Quirks.Profile, "xochip") {
+		_ = speaker.Init(
+			SampleRate,
+			SampleRate.N(time.Second/10),
+		)
+		myApp.pattern = audio.NewPatternStreamer(myApp.c8, SampleRate)
+		speaker.Play(myApp.pattern)
+	} else if cfg.Paths.Beep == "" {
+		voice, err := audio.ParseVoice(cfg.Audio.Pitch, cfg.Audio.Volume, cfg.Audio.Waveform)
+		if err != nil {
+			return nil, err
+		}
+		myApp.buzzer = audio.NewBuzzer(voice, SampleRate)
+		_ = speaker.Init(
+			SampleRate,
+			SampleRate.N(time.Second/30),
+		)
+		speaker.Play(myApp.buzzer)
+	} else {
+		absPathBeep, err := filepath.Abs(cfg.Paths.Beep)
 
-	var format beep.Format
-	myApp.beepStreamer, format, err = mp3.Decode(myApp.beepFile)
+		if err != nil {
+			panic(err)
+		}
+		myApp.beepFile, err = os.Open(absPathBeep)
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		var format beep.Format
+		myApp.beepStreamer, format, err = mp3.Decode(myApp.beepFile)
 
-	_ = speaker.Init(
-		format.SampleRate,
-		format.SampleRate.N(time.Second/10),
-	)
+		if err != nil {
+			return nil, err
+		}
 
-	cmdKeypad := make(keyhandlers.Cmd)
+		_ = speaker.Init(
+			format.SampleRate,
+			format.SampleRate.N(time.Second/10),
+		)
+	}
 
-	for k, v := range keyhandlers.KeyboardToKeypad {
-		newKey := v
-		cmdKeypad[k] = func() {
-			myApp.channel <- newKey
-		}
+	keyMap, err := keyhandlers.ParseKeyMap(cfg.Keys)
+	if err != nil {
+		return nil, err
 	}
-	myApp.keypad = keyhandlers.NewKeyHandler(myApp.window, &cmdKeypad)
+	myApp.keypad = keyhandlers.NewKeypadHandler(myApp.window, myApp.keyboardKeys, keyMap)
 
 	cmdKeyboard := make(keyhandlers.Cmd)
 	cmdKeyboard[pixelgl.KeyEscape] = func() {
 		myApp.c8.Close()
-		defer myApp.beepFile.Close()
-		defer myApp.beepStreamer.Close()
-		myApp.channel <- chip8.AsciiEscape
-
+		if myApp.beepFile != nil {
+			defer myApp.beepFile.Close()
+		}
 	}
+	myApp.addSlotKeys(cmdKeyboard)
+	myApp.addCaptureKeys(cmdKeyboard)
 	myApp.keyboard = keyhandlers.NewKeyHandler(myApp.window, &cmdKeyboard)
 
-	absPathFonts, err := filepath.Abs(cfg.Paths.Fonts)
-	if err != nil {
-		panic(err)
+	if cfg.Paths.Fonts != "" {
+		absPathFonts, err := filepath.Abs(cfg.Paths.Fonts)
+		if err != nil {
+			return nil, err
+		}
+		err = myApp.c8.LoadFonts(absPathFonts)
+		if err != nil {
+			return nil, err
+		}
 	}
-	err = myApp.c8.LoadFonts(absPathFonts)
 
-	if err != nil {
-		panic(err)
+	if cfg.Paths.Flags != "" {
+		absPathFlags, err := filepath.Abs(cfg.Paths.Flags)
+		if err != nil {
+			return nil, err
+		}
+		err = myApp.c8.LoadFlags(absPathFlags)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return myApp, nil
 }
 
-//Run loads the ROM given in the configuration into the chip8,
+//Run loads the ROM given in the configuration into the chip8, starts the movie which is recorded or played if there is one,
 //then runs the chip8 making a distinction if the configuration indicates whether the application should run in debug mode.
 func (myApp *App) Run() {
 	absPathRom, err := filepath.Abs(myApp.cfg.Paths.Rom)
@@ -126,6 +231,15 @@ func (myApp *App) Run() {
 	if err != nil {
 		panic(err)
 	}
+	if myApp.startState != "" {
+		err = myApp.loadState(myApp.startState)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if err = myApp.startMovie(); err != nil {
+		panic(err)
+	}
 	if myApp.cfg.Debug.On == "true" {
 		myApp.debugChip8()
 	} else {
@@ -139,88 +253,191 @@ func (myApp *App) runChip8() {
 	go myApp.keypad.ExecuteInputs()
 	go myApp.cycle()
 	myApp.update()
+	myApp.stop()
 
 }
 
+//cycle runs the chip8 frame by frame: 60 times per second it executes the instructions of a frame, tells the speaker whether it beeps and then ticks the timers,
+//so the speed of the chip8 doesn't change the timing of the programs, and the beep lasts as many frames as the sound timer counts back.
+//After every frame the state of the chip8 is saved into the history, and while the rewind key is held the frames are undone one by one,
+//except while a movie is recorded or played, because its frames can't be undone.
+//Every frame, including the ones undone, is added to the recording of the display if it's being recorded.
 func (myApp *App) cycle() {
-
-	clock := time.NewTicker(chip8.Frequency)
+	defer close(myApp.stopped)
+	frame := time.NewTicker(chip8.RefreshRate)
+	defer frame.Stop()
+	previous := new(chip8.Snapshot)
 	for !myApp.c8.IsClosed() {
-		select {
-		case <-clock.C:
-			{
-				myApp.c8.Cycle()
-
+		<-frame.C
+		myApp.runTasks()
+		if !myApp.inMovie() && myApp.window.Pressed(keyhandlers.KeyRewind) {
+			if myApp.history.Pop(previous) {
+				myApp.c8.Restore(previous)
 			}
+			myApp.sound(false)
+			myApp.recordFrame()
+			continue
 		}
-
+		myApp.latchKeys()
+		for k := 0; k < myApp.ipf && !myApp.c8.IsClosed(); k++ {
+			if err := myApp.c8.Cycle(); err != nil {
+				myApp.halt(err)
+				return
+			}
+		}
+		myApp.sound(myApp.c8.MustBeep())
+		myApp.c8.TickTimers()
+		myApp.recordFrame()
+		myApp.history.Push(myApp.c8.Snapshot())
 	}
 }
 
-//debugChip8 does the same that runChip8 with the distinction it use another frequency and save the state of the chip8 in every cycle
-//to store it into a json file
-func (myApp *App) debugChip8() {
-
-	_, err := os.Create(myApp.cfg.Debug.File)
+//halt is called when the chip8 fails executing the program, which can't continue.
+//It reports the fault, which is also shown on the screen by update until the app is closed.
+func (myApp *App) halt(err error) {
+	fmt.Fprintln(os.Stderr, "chip8 halted:", err)
+	myApp.faults <- err
+}
 
-	if err != nil {
-		panic(err)
+//debugChip8 runs the chip8 controlled by a debugger, which can stop it on breakpoints and execute it step by step.
+//The debugger is driven from a REPL on stdin and from the keyboard, and its state is drawn over the screen.
+//If the configuration has a file, the state of the chip8 after every instruction is saved into it as json.
+func (myApp *App) debugChip8() {
+	myApp.dbg = debugger.New(myApp.c8)
+	myApp.overlay = true
+
+	cmdDebug := make(keyhandlers.Cmd)
+	for k, v := range keyhandlers.KeyboardToDebugger {
+		cmd := v
+		cmdDebug[k] = func() {
+			myApp.debugCommand(cmd)
+		}
 	}
+	debugKeys := keyhandlers.NewKeyHandler(myApp.window, &cmdDebug)
 
+	fmt.Println("chip8 debugger, type help to see the commands")
+	go debugger.NewREPL(myApp.dbg, os.Stdin, os.Stdout).Run()
+	go myApp.keyboard.ExecuteInputs()
+	go myApp.keypad.ExecuteInputs()
+	go debugKeys.ExecuteInputs()
 	go myApp.cycleDebug()
-	for {
-		myApp.update()
+	myApp.update()
+	myApp.stop()
 
+}
+
+//stop waits for the goroutine which cycles the chip8 to return after the app is closed, and closes the trace, the recording and the movie
+func (myApp *App) stop() {
+	<-myApp.stopped
+	myApp.stopRecording()
+	myApp.stopMovie()
+	if myApp.tracer != nil {
+		if err := myApp.tracer.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "trace:", err)
+		}
 	}
+}
 
+//debugCommand executes a command of the debugger given by the keyboard
+func (myApp *App) debugCommand(cmd keyhandlers.DebugCmd) {
+	switch cmd {
+	case keyhandlers.DebugContinue:
+		if myApp.dbg.Paused() {
+			myApp.dbg.Continue()
+		} else {
+			myApp.dbg.Pause()
+		}
+	case keyhandlers.DebugStep:
+		myApp.dbg.Step()
+	case keyhandlers.DebugStepOver:
+		myApp.dbg.StepOver()
+	case keyhandlers.DebugStepOut:
+		_ = myApp.dbg.StepOut()
+	case keyhandlers.DebugBreakpoint:
+		myApp.dbg.ToggleBreakpoint()
+	case keyhandlers.DebugOverlay:
+		myApp.overlay = !myApp.overlay
+		myApp.c8.MustDraw = true
+	}
 }
+
+//cycleDebug runs the chip8 frame by frame like cycle, but through the debugger, which can pause it at any instruction.
+//While the chip8 is paused its timers aren't ticked, and it doesn't beep.
 func (myApp *App) cycleDebug() {
-	clock := time.NewTicker(chip8.FrequencyDebugMode)
+	defer close(myApp.stopped)
+	frame := time.NewTicker(chip8.RefreshRate)
+	defer frame.Stop()
 	var sChip8 []state.StateChip8
+	cycles := myApp.c8.Cycles()
 
-	for {
-		select {
-		case <-clock.C:
-			{
-				myApp.c8.Cycle()
-				if myApp.c8.IsClosed() {
-					return
-				}
-				sChip8 = append(sChip8, *myApp.c8.Dump())
-				stateBytes, err := json.Marshal(sChip8)
+	if myApp.cfg.Debug.File != "" {
+		_, err := os.Create(myApp.cfg.Debug.File)
+		if err != nil {
+			panic(err)
+		}
+	}
 
-				if err != nil {
-					panic(err)
-				}
+	for !myApp.c8.IsClosed() {
+		<-frame.C
+		myApp.runTasks()
+		myApp.latchKeys()
+		if myApp.dbg.Paused() {
+			myApp.sound(false)
+			continue
+		}
+		for k := 0; k < myApp.ipf && !myApp.dbg.Paused(); k++ {
+			if err := myApp.dbg.Cycle(); err != nil {
+				myApp.halt(err)
+				return
+			}
+			if myApp.cfg.Debug.File == "" || myApp.c8.Cycles() == cycles {
+				continue
+			}
+			cycles = myApp.c8.Cycles()
+			sChip8 = append(sChip8, *myApp.c8.Dump())
+			stateBytes, err := json.Marshal(sChip8)
 
-				err = ioutil.WriteFile(myApp.cfg.Debug.File, stateBytes, 0644)
-				if err != nil {
-					panic(err)
-				}
+			if err != nil {
+				panic(err)
+			}
+
+			err = ioutil.WriteFile(myApp.cfg.Debug.File, stateBytes, 0644)
+			if err != nil {
+				panic(err)
 			}
 		}
+		myApp.sound(myApp.c8.MustBeep())
+		myApp.c8.TickTimers()
+		myApp.recordFrame()
 	}
 }
 
-//update draws and beeps if it's needed, and executes the inputs.
+//update draws if it's needed, and executes the inputs. With the phosphor persistence it draws every frame, so the pixels fade out.
+//If the chip8 is halted by a fault, it draws the fault over the last frame and stops beeping.
+//In debug mode it also draws the state of the debugger when it changes.
 func (myApp *App) update() {
-	clock := time.NewTicker(chip8.Frequency)
+	clock := time.NewTicker(chip8.RefreshRate)
+	halted := false
 
 	for !myApp.c8.IsClosed() {
 		select {
+		case err := <-myApp.faults:
+			halted = true
+			speaker.Clear()
+			myApp.m.ToDraw(myApp.c8.GetFrameBuffer())
+			myApp.m.ToDrawText("HALTED\n" + err.Error() + "\nPress Esc to quit")
 		case <-clock.C:
 			{
-				if myApp.c8.MustDraw {
+				dirty := myApp.dbg != nil && myApp.dbg.Dirty()
+				fading := myApp.cfg.Display.Phosphor > 0
+				if !halted && (myApp.c8.MustDraw || dirty || fading) {
 					myApp.c8.MustDraw = false
 
 					myApp.m.ToDraw(myApp.c8.GetFrameBuffer())
+					if myApp.dbg != nil && myApp.overlay {
+						myApp.m.ToDrawText(myApp.dbg.Status())
+					}
 				}
-				if myApp.c8.MustBeep() {
-					speaker.Play(myApp.beepStreamer)
-					_ = myApp.beepStreamer.Seek(0)
-
-				}
-
 				myApp.window.Update()
 
 			}
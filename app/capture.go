@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/keyhandlers"
+	"github.com/NoetherianRing/Chip-8/recorder"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//addCaptureKeys adds to the commands of the keyboard the keys which save a screenshot of the display,
+//and start and stop recording it
+func (myApp *App) addCaptureKeys(cmd keyhandlers.Cmd) {
+	cmd[keyhandlers.KeyScreenshot] = func() {
+		myApp.runTask(myApp.screenshot)
+	}
+	cmd[keyhandlers.KeyRecord] = func() {
+		myApp.runTask(myApp.toggleRecording)
+	}
+}
+
+//captureFile returns a new file for a capture of the ROM with the given extension, in the captures directory of the configuration
+func (myApp *App) captureFile(ext string) (string, error) {
+	if dir := myApp.cfg.Paths.Captures; dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
+	rom := filepath.Base(myApp.cfg.Paths.Rom)
+	rom = strings.TrimSuffix(rom, filepath.Ext(rom))
+	return filepath.Join(myApp.cfg.Paths.Captures, rom+"-"+time.Now().Format("20060102-150405.000")+ext), nil
+}
+
+//screenshot saves the display as a PNG image
+func (myApp *App) screenshot() {
+	filename, err := myApp.captureFile(".png")
+	if err == nil {
+		var f *os.File
+		if f, err = os.Create(filename); err == nil {
+			err = recorder.Screenshot(f, myApp.c8.GetFrameBuffer(), myApp.palette, myApp.cfg.Record.Scale)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "screenshot:", err)
+		return
+	}
+	fmt.Println("screenshot saved in", filename)
+}
+
+//toggleRecording starts recording the display in the format of the configuration, or stops the recording and saves it
+func (myApp *App) toggleRecording() {
+	if myApp.recording != nil {
+		myApp.stopRecording()
+		return
+	}
+	format, err := recorder.ParseFormat(myApp.cfg.Record.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "recording:", err)
+		return
+	}
+	filename, err := myApp.captureFile(format.Extension())
+	if err == nil {
+		myApp.recording, err = recorder.Create(filename, myApp.palette, myApp.cfg.Record.Scale)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "recording:", err)
+		return
+	}
+	myApp.recordFile = filename
+	fmt.Println("recording to", filename)
+}
+
+//recordFrame adds the display to the recording, if the display is being recorded
+func (myApp *App) recordFrame() {
+	if myApp.recording != nil {
+		myApp.recording.Frame(myApp.c8.GetFrameBuffer())
+	}
+}
+
+//stopRecording saves the recording, if the display is being recorded
+func (myApp *App) stopRecording() {
+	if myApp.recording == nil {
+		return
+	}
+	duration := myApp.recording.Duration()
+	if err := myApp.recording.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "recording:", err)
+	} else {
+		fmt.Printf("recording of %.1fs saved in %s\n", duration.Seconds(), myApp.recordFile)
+	}
+	myApp.recording = nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/movie"
+	"github.com/NoetherianRing/Chip-8/romdb"
+	"io/ioutil"
+	"os"
+)
+
+//errMovie is returned when a save state is loaded while a movie is recorded or played, which would break the movie
+var errMovie = errors.New("the states can't be loaded while a movie is recorded or played")
+
+//RecordMovie makes the app record a movie of the session into filename, which is written when the app is closed
+func (myApp *App) RecordMovie(filename string) {
+	myApp.movieFile = filename
+}
+
+//PlayMovie makes the app play a movie from the start of the ROM: its keys are held down frame by frame, ignoring the keyboard until it ends.
+//The configuration must have the ROM, the quirks profile and the speed of the movie, the random numbers are taken from the movie.
+func (myApp *App) PlayMovie(m *movie.Movie) {
+	myApp.playback = m
+}
+
+//startMovie starts the movie which is recorded or played, after loading the ROM.
+//The chip8 draws the random numbers of the movie which is played, and a movie recorded at the same time has them too.
+func (myApp *App) startMovie() error {
+	random := myApp.cfg.Random.Source
+	if m := myApp.playback; m != nil {
+		option, err := m.RandomOption()
+		if err != nil {
+			return err
+		}
+		option(myApp.c8)
+		random = m.Random
+	}
+	if myApp.movieFile == "" {
+		return nil
+	}
+	rom, err := ioutil.ReadFile(myApp.cfg.Paths.Rom)
+	if err != nil {
+		return err
+	}
+	myApp.movie = movie.New(myApp.c8, romdb.Hash(rom), myApp.cfg.Quirks.Profile, random, myApp.ipf)
+	return nil
+}
+
+//inMovie reports if a movie is recorded or played
+func (myApp *App) inMovie() bool {
+	return myApp.movie != nil || myApp.playback != nil
+}
+
+//latchKeys sets the keys held down in the keypad of the chip8 during the next frame: the keys of the keyboard, or the ones of the movie while it's played.
+//The keys don't change in the middle of a frame, so the frames of a movie are played as they were recorded.
+func (myApp *App) latchKeys() {
+	keys := myApp.keyboardKeys.Keys()
+	if m := myApp.playback; m != nil && myApp.played < len(m.Frames) {
+		keys = m.Frames[myApp.played]
+		myApp.played++
+		if myApp.played == len(m.Frames) {
+			fmt.Println("the movie has ended, the keyboard controls the chip8")
+		}
+	}
+	if myApp.movie != nil {
+		myApp.movie.Frames = append(myApp.movie.Frames, keys)
+	}
+	myApp.keys.SetKeys(keys)
+}
+
+//stopMovie writes the movie which is recorded, if there is one
+func (myApp *App) stopMovie() {
+	if myApp.movie == nil {
+		return
+	}
+	if err := myApp.movie.Create(myApp.movieFile); err != nil {
+		fmt.Fprintln(os.Stderr, "movie:", err)
+		return
+	}
+	fmt.Printf("movie of %d frames saved into %s\n", len(myApp.movie.Frames), myApp.movieFile)
+}
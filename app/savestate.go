@@ -0,0 +1,99 @@
+package app
+
+import (
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/keyhandlers"
+	"github.com/faiface/pixel/pixelgl"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//NumberOfSlots is the number of save slots, which are saved with F1-F10 and loaded with Shift+F1-F10
+const NumberOfSlots = 10
+
+//SetStartState makes Run load the save state in filename after loading the ROM, so the program continues from it
+func (myApp *App) SetStartState(filename string) {
+	myApp.startState = filename
+}
+
+//addSlotKeys adds to the commands of the keyboard a key per save slot: F<n> saves the state of the chip8 into the slot n,
+//and Shift+F<n> loads it.
+func (myApp *App) addSlotKeys(cmd keyhandlers.Cmd) {
+	for slot := 1; slot <= NumberOfSlots; slot++ {
+		n := slot
+		cmd[pixelgl.KeyF1+pixelgl.Button(slot-1)] = func() {
+			if myApp.window.Pressed(pixelgl.KeyLeftShift) || myApp.window.Pressed(pixelgl.KeyRightShift) {
+				myApp.runTask(func() { myApp.report(myApp.loadState(myApp.slotFile(n)), "loaded", n) })
+			} else {
+				myApp.runTask(func() { myApp.report(myApp.saveState(myApp.slotFile(n)), "saved", n) })
+			}
+		}
+	}
+}
+
+//slotFile returns the file of a save slot of the ROM, in the states directory of the configuration
+func (myApp *App) slotFile(slot int) string {
+	rom := filepath.Base(myApp.cfg.Paths.Rom)
+	rom = strings.TrimSuffix(rom, filepath.Ext(rom))
+	return filepath.Join(myApp.cfg.Paths.States, fmt.Sprintf("%s.slot%d.state", rom, slot))
+}
+
+//report prints the result of saving or loading a slot
+func (myApp *App) report(err error, action string, slot int) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "slot %d: %v\n", slot, err)
+		return
+	}
+	fmt.Printf("slot %d %s\n", slot, action)
+}
+
+//runTask queues a task to be executed by the goroutine which cycles the chip8, between two frames.
+//If the queue is full the task is dropped.
+func (myApp *App) runTask(task func()) {
+	select {
+	case myApp.tasks <- task:
+	default:
+	}
+}
+
+//runTasks executes the queued tasks. It must only be called by the goroutine which cycles the chip8.
+func (myApp *App) runTasks() {
+	for {
+		select {
+		case task := <-myApp.tasks:
+			task()
+		default:
+			return
+		}
+	}
+}
+
+//saveState saves the state of the chip8 into filename
+func (myApp *App) saveState(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = myApp.c8.SaveState(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//loadState loads the save state in filename into the chip8, unless a movie is recorded or played
+func (myApp *App) loadState(filename string) error {
+	if myApp.inMovie() {
+		return errMovie
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return myApp.c8.LoadState(f)
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"github.com/faiface/beep/speaker"
+)
+
+//sound tells the speaker whether the chip8 beeps in the frame which is ending, it must be called once per frame before ticking the timers.
+//The buzzer plays the frame for 1/60 of a second. The beep file is played from the beginning when the chip8 starts beeping, and it's stopped when it stops.
+//The audio pattern of the XO-CHIP is copied with its pitch for the speaker, which plays it until the next frame.
+func (myApp *App) sound(beeping bool) {
+	switch {
+	case myApp.buzzer != nil:
+		myApp.buzzer.Frame(beeping)
+	case myApp.pattern != nil:
+		myApp.pattern.Frame(beeping)
+	case myApp.beepStreamer != nil && beeping && !myApp.beeping:
+		speaker.Lock()
+		_ = myApp.beepStreamer.Seek(0)
+		speaker.Unlock()
+		speaker.Play(myApp.beepStreamer)
+	case myApp.beepStreamer != nil && !beeping && myApp.beeping:
+		speaker.Clear()
+	}
+	myApp.beeping = beeping
+}
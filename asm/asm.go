@@ -0,0 +1,398 @@
+package asm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	Origin       = 0x200   //Address at which the ROMs are loaded
+	AddressSpace = 0x10000 //The program can't go beyond the 64KB of memory of XO-CHIP
+)
+
+var (
+	labelPattern    = regexp.MustCompile(`^([A-Za-z_.][A-Za-z0-9_.]*):(.*)$`)
+	constantPattern = regexp.MustCompile(`^([A-Za-z_.][A-Za-z0-9_.]*)\s*=\s*(.+)$`)
+)
+
+//Error is an error in a line of the source
+type Error struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+//Program is an assembled ROM
+type Program struct {
+	ROM     []byte
+	Symbols map[string]int //Address of every label and value of every constant
+}
+
+//statement is an instruction or data of the source, whose size is known before the labels are resolved
+type statement struct {
+	file string
+	line int
+	name string   //Upper case name of the instruction, empty for data
+	args []string //Operands of the instruction, or expressions of the bytes of data
+	data []byte   //Data which doesn't need to be evaluated, such as sprites
+}
+
+//constant is an expression assigned to a name, which is evaluated when it's used
+type constant struct {
+	file string
+	line int
+	expr string
+}
+
+type assembler struct {
+	statements []statement
+	labels     map[string]int
+	constants  map[string]constant
+	values     map[string]int  //Values of the constants already evaluated
+	evaluating map[string]bool //Constants being evaluated, to detect definitions that refer to themselves
+	including  []string        //Files being included, to detect includes that include themselves
+	addr       int
+}
+
+//Assemble assembles the source file filename. The files included by it are relative to its directory.
+func Assemble(filename string) (*Program, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return AssembleSource(filename, string(src))
+}
+
+//AssembleSource assembles the source src. The name of the file is used in the errors, and the files included by it are relative to its directory.
+//
+//Every line can have labels ("name:"), followed by an instruction with Cowgod's mnemonics, a directive or the definition of a constant ("name = expression").
+//The comments start with ;. The directives are:
+//	DB or :byte e1, e2, ...   bytes of data, separated by commas or spaces
+//	:sprite ..####.. .#....#.  bytes of a sprite, drawn with # and . (8 or 16 pixels per row)
+//	:const name expression     defines a constant
+//	:include "file"            assembles another file in this place
+func AssembleSource(filename string, src string) (*Program, error) {
+	a := &assembler{
+		labels:     map[string]int{},
+		constants:  map[string]constant{},
+		values:     map[string]int{},
+		evaluating: map[string]bool{},
+		addr:       Origin,
+	}
+	if err := a.parse(filename, src); err != nil {
+		return nil, err
+	}
+
+	program := &Program{Symbols: map[string]int{}}
+	for _, s := range a.statements {
+		bytes, err := a.assemble(s)
+		if err != nil {
+			return nil, &Error{File: s.file, Line: s.line, Err: err}
+		}
+		program.ROM = append(program.ROM, bytes...)
+	}
+	for name, addr := range a.labels {
+		program.Symbols[name] = addr
+	}
+	for name, c := range a.constants {
+		value, err := a.resolve(name)
+		if err != nil {
+			return nil, &Error{File: c.file, Line: c.line, Err: err}
+		}
+		program.Symbols[name] = value
+	}
+	return program, nil
+}
+
+//parse reads the statements, labels and constants of a file, which are assembled after every label is known
+func (a *assembler) parse(filename string, src string) error {
+	for _, f := range a.including {
+		if f == filename {
+			return fmt.Errorf("%s includes itself", filename)
+		}
+	}
+	a.including = append(a.including, filename)
+	defer func() { a.including = a.including[:len(a.including)-1] }()
+
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for line := 1; scanner.Scan(); line++ {
+		if err := a.parseLine(filename, line, scanner.Text()); err != nil {
+			var e *Error
+			if errors.As(err, &e) {
+				return err
+			}
+			return &Error{File: filename, Line: line, Err: err}
+		}
+	}
+	return nil
+}
+
+func (a *assembler) parseLine(filename string, line int, text string) error {
+	text = strings.TrimSpace(stripComment(text))
+
+	for {
+		m := labelPattern.FindStringSubmatch(text)
+		if m == nil {
+			break
+		}
+		if err := a.define(m[1]); err != nil {
+			return err
+		}
+		a.labels[m[1]] = a.addr
+		text = strings.TrimSpace(m[2])
+	}
+	if text == "" {
+		return nil
+	}
+
+	if m := constantPattern.FindStringSubmatch(text); m != nil {
+		return a.defineConstant(filename, line, m[1], m[2])
+	}
+
+	fields := strings.Fields(text)
+	directive := strings.ToLower(fields[0])
+	rest := strings.TrimSpace(text[len(fields[0]):])
+	s := statement{file: filename, line: line}
+
+	switch directive {
+	case "db", ":byte":
+		s.args = splitData(rest)
+		if len(s.args) == 0 {
+			return errors.New("missing bytes after " + fields[0])
+		}
+		return a.add(s, len(s.args))
+	case ":sprite":
+		for _, row := range fields[1:] {
+			bytes, err := spriteRow(row)
+			if err != nil {
+				return err
+			}
+			s.data = append(s.data, bytes...)
+		}
+		if len(s.data) == 0 {
+			return errors.New("missing rows after :sprite")
+		}
+		return a.add(s, len(s.data))
+	case ":const":
+		if len(fields) < 3 {
+			return errors.New("usage: :const name expression")
+		}
+		return a.defineConstant(filename, line, fields[1], strings.TrimSpace(rest[len(fields[1]):]))
+	case ":include":
+		return a.include(filename, strings.Trim(rest, `"`))
+	}
+	if strings.HasPrefix(directive, ":") {
+		return errors.New("unknown directive " + fields[0])
+	}
+
+	s.name = strings.ToUpper(fields[0])
+	s.args = splitOperands(rest)
+	return a.add(s, size(s.name, s.args))
+}
+
+//add adds a statement of n bytes
+func (a *assembler) add(s statement, n int) error {
+	if a.addr+n > AddressSpace {
+		return fmt.Errorf("the program doesn't fit in memory, it goes beyond %04X", AddressSpace-1)
+	}
+	a.statements = append(a.statements, s)
+	a.addr += n
+	return nil
+}
+
+//define checks name isn't already a label or a constant
+func (a *assembler) define(name string) error {
+	if _, ok := a.labels[name]; ok {
+		return errors.New("'" + name + "' is already defined")
+	}
+	if _, ok := a.constants[name]; ok {
+		return errors.New("'" + name + "' is already defined")
+	}
+	return nil
+}
+
+func (a *assembler) defineConstant(filename string, line int, name string, expr string) error {
+	if !isIdentifier(name) {
+		return errors.New("'" + name + "' is not a valid name")
+	}
+	if err := a.define(name); err != nil {
+		return err
+	}
+	a.constants[name] = constant{file: filename, line: line, expr: expr}
+	return nil
+}
+
+func (a *assembler) include(from string, filename string) error {
+	if filename == "" {
+		return errors.New("usage: :include \"file\"")
+	}
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(filepath.Dir(from), filename)
+	}
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return a.parse(filename, string(src))
+}
+
+//resolve returns the value of a label or a constant
+func (a *assembler) resolve(name string) (int, error) {
+	if addr, ok := a.labels[name]; ok {
+		return addr, nil
+	}
+	if value, ok := a.values[name]; ok {
+		return value, nil
+	}
+	c, ok := a.constants[name]
+	if !ok {
+		return 0, errors.New("undefined '" + name + "'")
+	}
+	if a.evaluating[name] {
+		return 0, errors.New("the value of '" + name + "' depends on itself")
+	}
+	a.evaluating[name] = true
+	defer delete(a.evaluating, name)
+
+	value, err := evaluate(c.expr, a.resolve)
+	if err != nil {
+		return 0, err
+	}
+	a.values[name] = value
+	return value, nil
+}
+
+//assemble returns the bytes of a statement
+func (a *assembler) assemble(s statement) ([]byte, error) {
+	if s.data != nil {
+		return s.data, nil
+	}
+	if s.name != "" {
+		return encode(s.name, s.args, a.resolve)
+	}
+	e := &encoder{args: s.args, resolve: a.resolve}
+	bytes := make([]byte, len(s.args))
+	for k := range s.args {
+		b, err := e.value(k, 8)
+		if err != nil {
+			return nil, err
+		}
+		bytes[k] = byte(b)
+	}
+	return bytes, nil
+}
+
+//WriteSymbols writes the labels and the constants of the program sorted by value, one per line, as definitions of constants.
+//So the symbols can be included by another source.
+func (p *Program) WriteSymbols(w io.Writer) error {
+	names := make([]string, 0, len(p.Symbols))
+	for name := range p.Symbols {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if p.Symbols[names[i]] != p.Symbols[names[j]] {
+			return p.Symbols[names[i]] < p.Symbols[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		if value := p.Symbols[name]; value >= 0 {
+			fmt.Fprintf(bw, "%s = #%03X\n", name, value)
+		} else {
+			fmt.Fprintf(bw, "%s = %d\n", name, value)
+		}
+	}
+	return bw.Flush()
+}
+
+//stripComment removes the comment of a line, which starts with ; outside of quotes
+func stripComment(line string) string {
+	quoted := false
+	for k, c := range line {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				return line[:k]
+			}
+		}
+	}
+	return line
+}
+
+//splitOperands splits the operands of an instruction, which are separated by commas
+func splitOperands(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var args []string
+	for _, arg := range strings.Split(s, ",") {
+		args = append(args, strings.TrimSpace(arg))
+	}
+	return args
+}
+
+//splitData splits the bytes of data, which are separated by commas or, if there aren't commas, by spaces outside of parentheses
+func splitData(s string) []string {
+	if strings.Contains(s, ",") {
+		return splitOperands(s)
+	}
+	var args []string
+	depth, start := 0, -1
+	for k, c := range s {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		}
+		if c == ' ' || c == '\t' {
+			if depth == 0 && start >= 0 {
+				args = append(args, s[start:k])
+				start = -1
+			}
+		} else if start < 0 {
+			start = k
+		}
+	}
+	if start >= 0 {
+		args = append(args, s[start:])
+	}
+	return args
+}
+
+//spriteRow converts a row of a sprite drawn with # (on) and . (off) into bytes, the row must have 8 or 16 pixels
+func spriteRow(row string) ([]byte, error) {
+	if len(row) != 8 && len(row) != 16 {
+		return nil, errors.New("the sprite row '" + row + "' must have 8 or 16 pixels")
+	}
+	bytes := make([]byte, len(row)/8)
+	for k, c := range row {
+		switch c {
+		case '#':
+			bytes[k/8] |= 0x80 >> uint(k%8)
+		case '.':
+		default:
+			return nil, errors.New("the sprite row '" + row + "' must only have # and .")
+		}
+	}
+	return bytes, nil
+}
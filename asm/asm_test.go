@@ -0,0 +1,128 @@
+package asm
+
+import (
+	"bytes"
+	"errors"
+	"github.com/NoetherianRing/Chip-8/disasm"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssembleSource(t *testing.T) {
+	src := `
+; draws a sprite and waits for a key
+speed = 2 * (1 + 1)
+:const START #0A
+
+main:   LD V0, START
+        LD V1, START + speed
+        LD I, ball
+        DRW V0, V1, ball.size
+loop:   LD V2, K
+        SHR V2
+        ADD V1, -1
+        LD [I], V0 - V2
+        LD I, long ball
+        JP loop
+
+ball:
+:sprite ..####.. .#....#.
+DB 0x42, $3C
+:byte 0b1000 (1 << 4) 255
+ball.size = . + 0
+`
+	_, err := AssembleSource("ball.asm", src)
+	assert.Error(t, err, "'.' is not a label")
+
+	src = src[:len(src)-len("ball.size = . + 0\n")] + "ball.size = end - ball\nend:\n"
+	program, err := AssembleSource("ball.asm", src)
+	assert.NoError(t, err, "")
+	assert.Equal(t, []byte{
+		0x60, 0x0A,
+		0x61, 0x0E,
+		0xA2, 0x16,
+		0xD0, 0x17,
+		0xF2, 0x0A,
+		0x82, 0x26,
+		0x71, 0xFF,
+		0x50, 0x22,
+		0xF0, 0x00, 0x02, 0x16,
+		0x12, 0x08,
+		0x3C, 0x42, 0x42, 0x3C, 0x08, 0x10, 0xFF,
+	}, program.ROM, "")
+	assert.Equal(t, 0x208, program.Symbols["loop"], "")
+	assert.Equal(t, 7, program.Symbols["ball.size"], "")
+	assert.Equal(t, 4, program.Symbols["speed"], "")
+
+	var symbols bytes.Buffer
+	assert.NoError(t, program.WriteSymbols(&symbols), "")
+	assert.Equal(t, "speed = #004\nball.size = #007\nSTART = #00A\nmain = #200\nloop = #208\nball = #216\nend = #21D\n", symbols.String(), "")
+}
+
+func TestAssemble_Include(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.asm"), []byte("CLS\n:include \"sprites.asm\"\n"), 0644), "")
+	_, err := Assemble(filepath.Join(dir, "main.asm"))
+	var e *Error
+	if assert.True(t, errors.As(err, &e), "missing include") {
+		assert.Equal(t, 2, e.Line, "")
+	}
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sprites.asm"), []byte("digit = #300\n"), 0644), "")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.asm"), []byte(":include \"sprites.asm\"\nLD I, digit\n"), 0644), "")
+	program, err := Assemble(filepath.Join(dir, "main.asm"))
+	assert.NoError(t, err, "")
+	assert.Equal(t, []byte{0xA3, 0x00}, program.ROM, "")
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "loop.asm"), []byte("CLS\n:include \"loop.asm\"\n"), 0644), "")
+	_, err = Assemble(filepath.Join(dir, "loop.asm"))
+	assert.Error(t, err, "include cycle")
+}
+
+func TestAssembleSource_Errors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+	}{
+		{"CLS\nJUMP #200", 2},
+		{"LD V0, #100", 1},
+		{"JP #1000", 1},
+		{"\n\nDRW V0, V1, 16", 3},
+		{"a:\na:", 2},
+		{"x = y\ny = x\nLD V0, x", 3},
+		{"LD V0, missing", 1},
+		{"SE I, 1", 1},
+		{":sprite ..##", 1},
+		{":org #300", 1},
+		{"LD V0, (1 + 2", 1},
+	}
+	for _, test := range tests {
+		_, err := AssembleSource("test.asm", test.src)
+		var e *Error
+		if assert.True(t, errors.As(err, &e), test.src) {
+			assert.Equal(t, test.line, e.Line, test.src)
+			assert.Equal(t, "test.asm", e.File, test.src)
+		}
+	}
+}
+
+//TestRoundTrip checks that the assembly written by the disassembler is assembled into the same ROM
+func TestRoundTrip(t *testing.T) {
+	roms, _ := filepath.Glob("../assets/*.ch8")
+	assert.NotEmpty(t, roms, "")
+	for _, rom := range roms {
+		data, err := ioutil.ReadFile(rom)
+		assert.NoError(t, err, "")
+
+		var src bytes.Buffer
+		_, err = disasm.Disassemble(data).WriteTo(&src)
+		assert.NoError(t, err, rom)
+
+		program, err := AssembleSource(rom, src.String())
+		if assert.NoError(t, err, rom) {
+			assert.Equal(t, data, program.ROM, rom)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package asm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	registerPattern      = regexp.MustCompile(`^[vV]([0-9a-fA-F])$`)
+	registerRangePattern = regexp.MustCompile(`^[vV]([0-9a-fA-F])\s*-\s*[vV]([0-9a-fA-F])$`)
+)
+
+//register parses a register V0 to VF
+func register(s string) (uint16, bool) {
+	m := registerPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	return hexDigit(m[1]), true
+}
+
+//registerRange parses a range of registers of XO-CHIP, such as "V2 - V5"
+func registerRange(s string) (uint16, uint16, bool) {
+	m := registerRangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	return hexDigit(m[1]), hexDigit(m[2]), true
+}
+
+func hexDigit(s string) uint16 {
+	return uint16(strings.IndexByte("0123456789abcdef", strings.ToLower(s)[0]))
+}
+
+//long parses the operand "long addr" of the XO-CHIP F000 NNNN, returning the expression of the address
+func long(s string) (string, bool) {
+	if len(s) > 5 && strings.EqualFold(s[:5], "long ") {
+		return strings.TrimSpace(s[5:]), true
+	}
+	return "", false
+}
+
+//size returns the number of bytes of an instruction
+func size(name string, args []string) int {
+	if name == "LD" && len(args) == 2 {
+		if _, ok := long(args[1]); ok {
+			return 4
+		}
+	}
+	return 2
+}
+
+//arithmetic are the instructions 8XYN
+var arithmetic = map[string]uint16{
+	"OR": 0x1, "AND": 0x2, "XOR": 0x3, "SUB": 0x5, "SHR": 0x6, "SUBN": 0x7, "SHL": 0xE,
+}
+
+//noOperands are the instructions without operands
+var noOperands = map[string]uint16{
+	"CLS": 0x00E0, "RET": 0x00EE, "SCR": 0x00FB, "SCL": 0x00FC, "EXIT": 0x00FD, "LOW": 0x00FE, "HIGH": 0x00FF, "AUDIO": 0xF002,
+}
+
+//loadFrom and loadTo are the LD instructions between a register and something which isn't a register, such as LD Vx, DT and LD DT, Vx
+var loadFrom = map[string]uint16{"DT": 0x07, "K": 0x0A, "[I]": 0x65, "R": 0x85}
+var loadTo = map[string]uint16{"DT": 0x15, "ST": 0x18, "F": 0x29, "HF": 0x30, "B": 0x33, "[I]": 0x55, "R": 0x75}
+
+//encoder encodes the operands of an instruction
+type encoder struct {
+	args    []string
+	resolve resolver
+}
+
+//value evaluates the operand k, checking it fits in bits bits.
+//Negative values are accepted for bytes, so ADD V0, -1 can be written instead of ADD V0, #FF.
+func (e *encoder) value(k int, bits uint) (uint16, error) {
+	v, err := evaluate(e.args[k], e.resolve)
+	if err != nil {
+		return 0, err
+	}
+	max := 1<<bits - 1
+	if v > max || (bits == 8 && v < -128) || (bits != 8 && v < 0) {
+		return 0, fmt.Errorf("'%s' (%d) doesn't fit in %d bits", e.args[k], v, bits)
+	}
+	return uint16(v) & uint16(max), nil
+}
+
+//encode assembles an instruction. The name must be upper case.
+func encode(name string, args []string, resolve resolver) ([]byte, error) {
+	oc, err := opcode(name, args, &encoder{args: args, resolve: resolve})
+	if err != nil {
+		return nil, err
+	}
+	bytes := []byte{byte(oc >> 8), byte(oc)}
+	if oc == 0xF000 {
+		expr, _ := long(args[1])
+		e := &encoder{args: []string{expr}, resolve: resolve}
+		addr, err := e.value(0, 16)
+		if err != nil {
+			return nil, err
+		}
+		bytes = append(bytes, byte(addr>>8), byte(addr))
+	}
+	return bytes, nil
+}
+
+func opcode(name string, args []string, e *encoder) (uint16, error) {
+	invalid := fmt.Errorf("invalid operands for %s: '%s'", name, strings.Join(args, ", "))
+
+	if oc, ok := noOperands[name]; ok {
+		if len(args) != 0 {
+			return 0, invalid
+		}
+		return oc, nil
+	}
+
+	var x, y uint16
+	var isX, isY bool
+	if len(args) > 0 {
+		x, isX = register(args[0])
+	}
+	if len(args) > 1 {
+		y, isY = register(args[1])
+	}
+	xy := x<<8 | y<<4
+
+	switch name {
+	case "SCD", "SCU", "PLANE":
+		if len(args) != 1 {
+			return 0, invalid
+		}
+		n, err := e.value(0, 4)
+		base := map[string]uint16{"SCD": 0x00C0, "SCU": 0x00D0, "PLANE": 0xF001}[name]
+		if name == "PLANE" {
+			n <<= 8
+		}
+		return base | n, err
+	case "JP":
+		if len(args) == 2 && isX && x == 0 {
+			nnn, err := e.value(1, 12)
+			return 0xB000 | nnn, err
+		}
+		if len(args) != 1 {
+			return 0, invalid
+		}
+		nnn, err := e.value(0, 12)
+		return 0x1000 | nnn, err
+	case "CALL":
+		if len(args) != 1 {
+			return 0, invalid
+		}
+		nnn, err := e.value(0, 12)
+		return 0x2000 | nnn, err
+	case "SE", "SNE":
+		if len(args) != 2 || !isX {
+			return 0, invalid
+		}
+		if isY {
+			return map[string]uint16{"SE": 0x5000, "SNE": 0x9000}[name] | xy, nil
+		}
+		kk, err := e.value(1, 8)
+		return map[string]uint16{"SE": 0x3000, "SNE": 0x4000}[name] | x<<8 | kk, err
+	case "ADD":
+		if len(args) != 2 {
+			return 0, invalid
+		}
+		if strings.EqualFold(args[0], "I") && isY {
+			return 0xF01E | y<<8, nil
+		}
+		if !isX {
+			return 0, invalid
+		}
+		if isY {
+			return 0x8004 | xy, nil
+		}
+		kk, err := e.value(1, 8)
+		return 0x7000 | x<<8 | kk, err
+	case "OR", "AND", "XOR", "SUB", "SUBN", "SHR", "SHL":
+		//SHR Vx and SHL Vx shift Vx into itself, so they work with and without the shift quirk
+		if len(args) == 1 && isX && (name == "SHR" || name == "SHL") {
+			return 0x8000 | x<<8 | x<<4 | arithmetic[name], nil
+		}
+		if len(args) != 2 || !isX || !isY {
+			return 0, invalid
+		}
+		return 0x8000 | xy | arithmetic[name], nil
+	case "MUL", "DIV":
+		if len(args) != 2 || !isX || !isY {
+			return 0, invalid
+		}
+		return map[string]uint16{"MUL": 0x9001, "DIV": 0x9002}[name] | xy, nil
+	case "RND":
+		if len(args) != 2 || !isX {
+			return 0, invalid
+		}
+		kk, err := e.value(1, 8)
+		return 0xC000 | x<<8 | kk, err
+	case "DRW":
+		if len(args) != 3 || !isX || !isY {
+			return 0, invalid
+		}
+		n, err := e.value(2, 4)
+		return 0xD000 | xy | n, err
+	case "SKP", "SKNP", "PITCH":
+		if len(args) != 1 || !isX {
+			return 0, invalid
+		}
+		return map[string]uint16{"SKP": 0xE09E, "SKNP": 0xE0A1, "PITCH": 0xF03A}[name] | x<<8, nil
+	case "LD":
+		if len(args) != 2 {
+			return 0, invalid
+		}
+		return load(args, e, x, y, isX, isY)
+	}
+	return 0, fmt.Errorf("unknown instruction '%s'", name)
+}
+
+//load assembles the LD instructions
+func load(args []string, e *encoder, x, y uint16, isX, isY bool) (uint16, error) {
+	invalid := fmt.Errorf("invalid operands for LD: '%s'", strings.Join(args, ", "))
+	dst, src := strings.ToUpper(args[0]), strings.ToUpper(args[1])
+
+	switch {
+	case isX && isY:
+		return 0x8000 | x<<8 | y<<4, nil
+	case dst == "I":
+		if _, ok := long(args[1]); ok {
+			return 0xF000, nil
+		}
+		nnn, err := e.value(1, 12)
+		return 0xA000 | nnn, err
+	case isX:
+		if n, ok := loadFrom[src]; ok {
+			return 0xF000 | x<<8 | n, nil
+		}
+		kk, err := e.value(1, 8)
+		return 0x6000 | x<<8 | kk, err
+	case isY:
+		if n, ok := loadTo[dst]; ok {
+			return 0xF000 | y<<8 | n, nil
+		}
+	case dst == "[I]":
+		if x, y, ok := registerRange(args[1]); ok {
+			return 0x5002 | x<<8 | y<<4, nil
+		}
+	case src == "[I]":
+		if x, y, ok := registerRange(args[0]); ok {
+			return 0x5003 | x<<8 | y<<4, nil
+		}
+	}
+	return 0, invalid
+}
@@ -0,0 +1,197 @@
+package asm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//resolver returns the value of a label or a constant
+type resolver func(name string) (int, error)
+
+//evaluate returns the value of an expression. The expressions can use numbers, labels and constants,
+//the binary operators | ^ & << >> + - * / %, the unary operators - and ~, and parentheses.
+//The numbers can be decimal, hexadecimal with the prefixes #, $ or 0x, or binary with the prefix 0b.
+func evaluate(expr string, resolve resolver) (int, error) {
+	p := &parser{input: expr, resolve: resolve}
+	if err := p.tokenize(); err != nil {
+		return 0, err
+	}
+	if len(p.tokens) == 0 {
+		return 0, errors.New("missing expression")
+	}
+	value, err := p.binary(0)
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("unexpected '%s' in '%s'", p.tokens[p.pos], expr)
+	}
+	return value, nil
+}
+
+//precedence of the binary operators, from the lowest to the highest
+var precedence = [][]string{
+	{"|"},
+	{"^"},
+	{"&"},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+type parser struct {
+	input   string
+	tokens  []string
+	pos     int
+	resolve resolver
+}
+
+func (p *parser) tokenize() error {
+	s := p.input
+	for k := 0; k < len(s); {
+		c := rune(s[k])
+		switch {
+		case unicode.IsSpace(c):
+			k++
+		case strings.HasPrefix(s[k:], "<<") || strings.HasPrefix(s[k:], ">>"):
+			p.tokens = append(p.tokens, s[k:k+2])
+			k += 2
+		case strings.ContainsRune("|^&+-*/%~()", c):
+			p.tokens = append(p.tokens, s[k:k+1])
+			k++
+		case c == '#' || c == '$' || c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			end := k + 1
+			for end < len(s) && (s[end] == '_' || s[end] == '.' || unicode.IsLetter(rune(s[end])) || unicode.IsDigit(rune(s[end]))) {
+				end++
+			}
+			p.tokens = append(p.tokens, s[k:end])
+			k = end
+		default:
+			return fmt.Errorf("unexpected '%c' in '%s'", c, s)
+		}
+	}
+	return nil
+}
+
+//binary parses the binary operators of the given level of precedence or higher
+func (p *parser) binary(level int) (int, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return 0, err
+	}
+	for p.pos < len(p.tokens) && contains(precedence[level], p.tokens[p.pos]) {
+		op := p.tokens[p.pos]
+		p.pos++
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "|":
+			left |= right
+		case "^":
+			left ^= right
+		case "&":
+			left &= right
+		case "<<":
+			left <<= uint(right)
+		case ">>":
+			left >>= uint(right)
+		case "+":
+			left += right
+		case "-":
+			left -= right
+		case "*":
+			left *= right
+		case "/", "%":
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			if op == "/" {
+				left /= right
+			} else {
+				left %= right
+			}
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (int, error) {
+	if p.pos == len(p.tokens) {
+		return 0, fmt.Errorf("incomplete expression '%s'", p.input)
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch token {
+	case "-":
+		value, err := p.unary()
+		return -value, err
+	case "~":
+		value, err := p.unary()
+		return ^value, err
+	case "(":
+		value, err := p.binary(0)
+		if err != nil {
+			return 0, err
+		}
+		if p.pos == len(p.tokens) || p.tokens[p.pos] != ")" {
+			return 0, fmt.Errorf("missing ')' in '%s'", p.input)
+		}
+		p.pos++
+		return value, nil
+	}
+	if value, ok, err := number(token); ok {
+		return value, err
+	}
+	if isIdentifier(token) {
+		return p.resolve(token)
+	}
+	return 0, fmt.Errorf("unexpected '%s' in '%s'", token, p.input)
+}
+
+//number parses a number token, it returns false if the token isn't a number
+func number(token string) (int, bool, error) {
+	base, digits := 10, token
+	lower := strings.ToLower(token)
+	switch {
+	case strings.HasPrefix(token, "#") || strings.HasPrefix(token, "$"):
+		base, digits = 16, token[1:]
+	case strings.HasPrefix(lower, "0x"):
+		base, digits = 16, token[2:]
+	case strings.HasPrefix(lower, "0b"):
+		base, digits = 2, token[2:]
+	case !unicode.IsDigit(rune(token[0])):
+		return 0, false, nil
+	}
+	value, err := strconv.ParseInt(digits, base, 32)
+	if err != nil {
+		return 0, true, fmt.Errorf("'%s' is not a valid number", token)
+	}
+	return int(value), true, nil
+}
+
+//isIdentifier reports if s is a valid name for a label or a constant
+func isIdentifier(s string) bool {
+	for k, c := range s {
+		if !(c == '_' || c == '.' || unicode.IsLetter(c) || (k > 0 && unicode.IsDigit(c))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
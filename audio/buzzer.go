@@ -0,0 +1,146 @@
+package audio
+
+import (
+	"fmt"
+	"github.com/faiface/beep"
+	"math"
+	"strings"
+)
+
+const (
+	BeepFrequency   = 440 //Pitch of the beep when the configuration doesn't give one, in Hz
+	FramesPerSecond = 60  //Frames of the chip8 per second, the sound timer counts back once per frame
+	FrameQueue      = 8   //Frames a Buzzer keeps waiting to be played, the ones given when it's full are dropped
+)
+
+//Waveform is the shape of the wave of the beep
+type Waveform int
+
+const (
+	Square Waveform = iota
+	Triangle
+	Sawtooth
+	Sine
+)
+
+//ParseWaveform parses the name of a waveform: square, triangle, sawtooth or sine
+func ParseWaveform(s string) (Waveform, error) {
+	switch strings.ToLower(s) {
+	case "", "square":
+		return Square, nil
+	case "triangle":
+		return Triangle, nil
+	case "sawtooth":
+		return Sawtooth, nil
+	case "sine":
+		return Sine, nil
+	}
+	return Square, fmt.Errorf("unknown waveform '%s', expected square, triangle, sawtooth or sine", s)
+}
+
+//value returns the value of the wave, from -1 to 1, at phase, which goes from 0 to 1 in a period
+func (w Waveform) value(phase float64) float64 {
+	switch w {
+	case Triangle:
+		return 4*math.Abs(phase-0.5) - 1
+	case Sawtooth:
+		return 2*phase - 1
+	case Sine:
+		return math.Sin(2 * math.Pi * phase)
+	}
+	if phase < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+//Voice is the sound of the beep
+type Voice struct {
+	Pitch    float64 //Frequency of the wave in Hz
+	Volume   float64 //Amplitude of the samples, 1 is the maximum that the speaker can play
+	Waveform Waveform
+}
+
+//DefaultVoice is a square wave of BeepFrequency, as loud as the audio pattern
+var DefaultVoice = Voice{Pitch: BeepFrequency, Volume: Volume, Waveform: Square}
+
+//ParseVoice returns the voice with the pitch, the volume and the name of the waveform of the configuration.
+//Without a pitch it's BeepFrequency.
+func ParseVoice(pitch, volume float64, waveform string) (Voice, error) {
+	w, err := ParseWaveform(waveform)
+	if err != nil {
+		return Voice{}, err
+	}
+	if pitch <= 0 {
+		pitch = BeepFrequency
+	}
+	if volume < 0 || volume > 1 {
+		return Voice{}, fmt.Errorf("the volume %g is out of range, expected a value from 0 to 1", volume)
+	}
+	return Voice{Pitch: pitch, Volume: volume, Waveform: w}, nil
+}
+
+//Buzzer is a beep.Streamer which synthesizes the beep of the chip8, a wave which sounds while the sound timer is active.
+//It plays the frames of the chip8 one after the other, each of them for 1/60 of a second, so the beep lasts exactly as many frames as the sound timer counts back,
+//and the wave goes on from frame to frame without restarting. The rest of the time it plays silence, so it can be played once and never stops.
+//If the frames arrive later than they're played, the last one is repeated.
+type Buzzer struct {
+	voice      Voice
+	sampleRate beep.SampleRate
+	step       float64   //Fraction of a period of the wave per sample
+	frames     chan bool //Whether the chip8 beeps in the frames not played yet
+	gate       bool      //Whether the chip8 beeps in the frame being played
+	frame      int       //Frames played
+	played     int       //Samples played
+	end        int       //Sample at which the frame being played ends
+	phase      float64   //Position in the period of the wave, from 0 to 1
+}
+
+//NewBuzzer instantiates a Buzzer which produces samples of the voice at sampleRate
+func NewBuzzer(voice Voice, sampleRate beep.SampleRate) *Buzzer {
+	return &Buzzer{
+		voice:      voice,
+		sampleRate: sampleRate,
+		step:       voice.Pitch / float64(sampleRate),
+		frames:     make(chan bool, FrameQueue),
+	}
+}
+
+//Frame adds a frame to play, in which the buzzer beeps if on is true. It must be called at the end of every frame of the chip8,
+//before ticking its timers, with chip8.MustBeep. It doesn't block, so it can be called from the goroutine which cycles the chip8.
+func (b *Buzzer) Frame(on bool) {
+	select {
+	case b.frames <- on:
+	default:
+	}
+}
+
+//Stream fills samples with the wave during the frames in which the chip8 beeps, and with silence during the rest
+func (b *Buzzer) Stream(samples [][2]float64) (n int, ok bool) {
+	for i := range samples {
+		if b.played == b.end {
+			select {
+			case b.gate = <-b.frames:
+			default:
+			}
+			b.frame++
+			b.end = b.frame * int(b.sampleRate) / FramesPerSecond
+		}
+		b.played++
+
+		value := 0.0
+		if b.gate {
+			value = b.voice.Volume * b.voice.Waveform.value(b.phase)
+			b.phase = math.Mod(b.phase+b.step, 1)
+		} else {
+			b.phase = 0
+		}
+		samples[i] = [2]float64{value, value}
+	}
+	return len(samples), true
+}
+
+//Err never returns an error, the stream is synthesized
+func (b *Buzzer) Err() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseWaveform(t *testing.T) {
+	w, err := ParseWaveform("Sine")
+	assert.NoError(t, err, "")
+	assert.Equal(t, Sine, w, "")
+	w, err = ParseWaveform("")
+	assert.NoError(t, err, "")
+	assert.Equal(t, Square, w, "the default waveform")
+	_, err = ParseWaveform("noise")
+	assert.Error(t, err, "")
+}
+
+func TestParseVoice(t *testing.T) {
+	v, err := ParseVoice(0, 0.5, "triangle")
+	assert.NoError(t, err, "")
+	assert.Equal(t, Voice{Pitch: BeepFrequency, Volume: 0.5, Waveform: Triangle}, v, "")
+	_, err = ParseVoice(440, 2, "square")
+	assert.Error(t, err, "")
+}
+
+func TestBuzzer(t *testing.T) {
+	//With a sample rate of 240 a frame has 4 samples, and a wave of 60Hz has a period of 4 samples
+	b := NewBuzzer(Voice{Pitch: 60, Volume: 0.5, Waveform: Square}, 240)
+	b.Frame(true)
+	b.Frame(true)
+	b.Frame(false)
+	samples := make([][2]float64, 14)
+	n, ok := b.Stream(samples[:6])
+	assert.True(t, ok, "")
+	assert.Equal(t, 6, n, "")
+	b.Stream(samples[6:])
+
+	values := make([]float64, len(samples))
+	for k, s := range samples {
+		values[k] = s[0]
+	}
+	assert.Equal(t, []float64{0.5, 0.5, -0.5, -0.5, 0.5, 0.5, -0.5, -0.5, 0, 0, 0, 0, 0, 0}, values, "the beep lasts two frames, and the last frame is repeated")
+
+	b.Frame(true)
+	b.Stream(samples[:4])
+	assert.Equal(t, 0.0, samples[1][0], "the end of the frame being played")
+	assert.Equal(t, 0.5, samples[2][0], "the wave starts again from the beginning")
+}
+
+func TestWaveform(t *testing.T) {
+	assert.Equal(t, 1.0, Triangle.value(0), "")
+	assert.Equal(t, -1.0, Triangle.value(0.5), "")
+	assert.Equal(t, -1.0, Sawtooth.value(0), "")
+	assert.InDelta(t, 1.0, Sine.value(0.25), 1e-9, "")
+	assert.Equal(t, -1.0, Square.value(0.75), "")
+}
+
+func TestWAV(t *testing.T) {
+	var out bytes.Buffer
+	b := NewBuzzer(Voice{Pitch: 60, Volume: 1, Waveform: Square}, 240)
+	w := NewWAV(&out, b, 240)
+	for _, on := range []bool{true, false, true} {
+		b.Frame(on)
+		w.Frame()
+	}
+	assert.Equal(t, 50*time.Millisecond, w.Duration(), "")
+	assert.NoError(t, w.Close(), "")
+
+	data := out.Bytes()
+	assert.Equal(t, 44+12*2, len(data), "")
+	assert.Equal(t, "RIFF", string(data[:4]), "")
+	assert.Equal(t, "WAVEfmt ", string(data[8:16]), "")
+	assert.Equal(t, uint32(240), binary.LittleEndian.Uint32(data[24:]), "")
+	assert.Equal(t, uint32(24), binary.LittleEndian.Uint32(data[40:]), "")
+	sample := func(k int) int16 {
+		return int16(binary.LittleEndian.Uint16(data[44+2*k:]))
+	}
+	assert.Equal(t, []int16{32767, -32767, 0, 32767}, []int16{sample(0), sample(2), sample(4), sample(8)}, "")
+}
@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"github.com/faiface/beep"
+	"math"
+	"sync"
+)
+
+const (
+	PatternBits = 128 //The XO-CHIP audio pattern buffer has 16 bytes, which are played bit by bit
+	Volume      = 0.2 //Amplitude of the samples, 1 is the maximum that the speaker can play
+)
+
+//Source is the chip8 as seen by the speaker: it says which audio pattern and pitch it must play
+type Source interface {
+	AudioPattern() ([PatternBits / 8]byte, byte)
+}
+
+//PatternStreamer is a beep.Streamer which synthesizes the 1-bit audio stream of the XO-CHIP.
+//While the sound timer of the chip8 is active, it plays the bits of the audio pattern in a loop, a bit on is a high sample and a bit off a low one.
+//The rest of the time it plays silence, so it can be played once and never stops.
+//The speaker doesn't read the chip8: Frame copies its state into the streamer, under a lock since Stream is called from the goroutine of the speaker.
+type PatternStreamer struct {
+	source     Source
+	sampleRate beep.SampleRate
+	position   float64 //Bit of the pattern that is being played. It's a float because usually a bit lasts a fraction of sample
+
+	lock    sync.Mutex
+	on      bool                  //Whether the chip8 beeps in the last frame
+	pattern [PatternBits / 8]byte //Audio pattern of the last frame
+	pitch   byte                  //Pitch of the last frame
+}
+
+//NewPatternStreamer instantiates a PatternStreamer which produces samples at sampleRate reading the audio pattern of source
+func NewPatternStreamer(source Source, sampleRate beep.SampleRate) *PatternStreamer {
+	return &PatternStreamer{source: source, sampleRate: sampleRate}
+}
+
+//Frame copies the audio pattern and the pitch of the source into the streamer, which plays them if on is true. It must be called at the end of every frame
+//of the chip8, before ticking its timers, with chip8.MustBeep, from the goroutine which cycles the chip8.
+func (p *PatternStreamer) Frame(on bool) {
+	pattern, pitch := p.source.AudioPattern()
+	p.lock.Lock()
+	p.on, p.pattern, p.pitch = on, pattern, pitch
+	p.lock.Unlock()
+}
+
+//PlaybackRate returns how many bits of the audio pattern are played per second with the given pitch.
+//It's 4000 for the default pitch of 64, and it doubles every 48 steps
+func PlaybackRate(pitch byte) float64 {
+	return 4000 * math.Pow(2, (float64(pitch)-64)/48)
+}
+
+//Stream fills samples with the audio pattern of the last frame, or with silence if the chip8 didn't beep in it
+func (p *PatternStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	p.lock.Lock()
+	on, pattern, pitch := p.on, p.pattern, p.pitch
+	p.lock.Unlock()
+	if !on {
+		p.position = 0
+		for i := range samples {
+			samples[i] = [2]float64{}
+		}
+		return len(samples), true
+	}
+
+	step := PlaybackRate(pitch) / float64(p.sampleRate)
+	for i := range samples {
+		bit := int(p.position)
+		value := -Volume
+		if pattern[bit/8]&(0x80>>(bit%8)) != 0 {
+			value = Volume
+		}
+		samples[i] = [2]float64{value, value}
+		p.position = math.Mod(p.position+step, PatternBits)
+	}
+	return len(samples), true
+}
+
+//Err never returns an error, the stream is synthesized
+func (p *PatternStreamer) Err() error {
+	return nil
+}
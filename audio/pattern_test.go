@@ -0,0 +1,50 @@
+package audio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type source struct {
+	pattern [16]byte
+	pitch   byte
+}
+
+func (s *source) AudioPattern() ([16]byte, byte) {
+	return s.pattern, s.pitch
+}
+
+func TestPlaybackRate(t *testing.T) {
+	assert.Equal(t, 4000.0, PlaybackRate(64), "default pitch")
+	assert.Equal(t, 8000.0, PlaybackRate(112), "an octave higher")
+}
+
+func TestPatternStreamer_Stream(t *testing.T) {
+	src := &source{pitch: 64}
+	src.pattern[0] = 0xF0
+	//With a sample rate of 4000 every sample plays one bit of the pattern
+	p := NewPatternStreamer(src, 4000)
+
+	samples := make([][2]float64, 8)
+	p.Frame(false)
+	n, ok := p.Stream(samples)
+	assert.True(t, ok, "")
+	assert.Equal(t, 8, n, "")
+	assert.Equal(t, [2]float64{}, samples[0], "silence while the sound timer is off")
+
+	p.Frame(true)
+	p.Stream(samples)
+	for k := 0; k < 4; k++ {
+		assert.Equal(t, Volume, samples[k][0], "bit on")
+		assert.Equal(t, -Volume, samples[k+4][0], "bit off")
+	}
+
+	for k := 1; k < 16; k++ {
+		src.pattern[k] = 0xFF
+	}
+	p.Stream(samples)
+	assert.Equal(t, -Volume, samples[0][0], "the pattern of the last frame until Frame is called")
+	p.Frame(true)
+	p.Stream(samples)
+	assert.Equal(t, Volume, samples[0][0], "")
+}
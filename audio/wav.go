@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"encoding/binary"
+	"github.com/faiface/beep"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+//WAV renders the audio of a chip8 frame by frame into a WAV file with 16 bits mono samples.
+//The samples are kept in memory, and the file is written when it's closed, because its header has their size.
+type WAV struct {
+	w          io.Writer
+	streamer   beep.Streamer
+	sampleRate beep.SampleRate
+	data       []byte //Samples rendered, little endian
+	frames     int    //Frames rendered
+	buf        [][2]float64
+}
+
+//NewWAV instantiates a WAV which renders the samples of streamer, produced at sampleRate, and writes them to w when it's closed
+func NewWAV(w io.Writer, streamer beep.Streamer, sampleRate beep.SampleRate) *WAV {
+	return &WAV{w: w, streamer: streamer, sampleRate: sampleRate}
+}
+
+//CreateWAV creates the file filename, and instantiates a WAV which writes to it
+func CreateWAV(filename string, streamer beep.Streamer, sampleRate beep.SampleRate) (*WAV, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewWAV(f, streamer, sampleRate), nil
+}
+
+//Frame renders the samples of a frame of the chip8, 1/60 of a second. It must be called at the end of every frame, before ticking the timers of the chip8,
+//and after Buzzer.Frame if the streamer is a Buzzer. If the streamer ends, the rest of the frame is silence.
+func (w *WAV) Frame() {
+	w.frames++
+	n := w.frames*int(w.sampleRate)/FramesPerSecond - len(w.data)/2
+	if cap(w.buf) < n {
+		w.buf = make([][2]float64, n)
+	}
+	samples := w.buf[:n]
+	for filled := 0; filled < n; {
+		streamed, ok := w.streamer.Stream(samples[filled:])
+		filled += streamed
+		if !ok {
+			for i := filled; i < n; i++ {
+				samples[i] = [2]float64{}
+			}
+			break
+		}
+	}
+	for _, sample := range samples {
+		value := math.Max(-1, math.Min(1, (sample[0]+sample[1])/2))
+		w.data = append(w.data, 0, 0)
+		binary.LittleEndian.PutUint16(w.data[len(w.data)-2:], uint16(int16(value*math.MaxInt16)))
+	}
+}
+
+//Duration returns how long the audio rendered lasts
+func (w *WAV) Duration() time.Duration {
+	return w.sampleRate.D(len(w.data) / 2)
+}
+
+//Close writes the WAV file, and closes the writer if it's an io.Closer
+func (w *WAV) Close() error {
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+len(w.data)))
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)                     //Size of the format
+	binary.LittleEndian.PutUint16(header[20:], 1)                      //PCM
+	binary.LittleEndian.PutUint16(header[22:], 1)                      //Channels
+	binary.LittleEndian.PutUint32(header[24:], uint32(w.sampleRate))   //Samples per second
+	binary.LittleEndian.PutUint32(header[28:], uint32(w.sampleRate)*2) //Bytes per second
+	binary.LittleEndian.PutUint16(header[32:], 2)                      //Bytes per sample
+	binary.LittleEndian.PutUint16(header[34:], 16)                     //Bits per sample
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(len(w.data)))
+
+	_, err := w.w.Write(header)
+	if err == nil {
+		_, err = w.w.Write(w.data)
+	}
+	if closer, ok := w.w.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package chip8
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeTable(t *testing.T) {
+	d := decodeTable[0x8AB4]
+	assert.NotNil(t, d.execute, "ADD VA, VB")
+	assert.Equal(t, uint8(0xA), d.x, "")
+	assert.Equal(t, uint8(0xB), d.y, "")
+	assert.Equal(t, uint16(0x4), d.n, "")
+
+	d = decodeTable[0xA2F0]
+	assert.Equal(t, uint16(0x2F0), d.nnn, "")
+	d = decodeTable[0x6C3E]
+	assert.Equal(t, uint8(0x3E), d.kk, "")
+
+	for _, unknown := range []uint16{0x8AB8, 0xE19F, 0xF0FF, 0x0123} {
+		assert.Nil(t, decodeTable[unknown].execute, "%04X", unknown)
+	}
+	for id := range instructionSet {
+		assert.NotNil(t, decodeTable[id].execute, "%04X", id)
+	}
+}
+
+//BenchmarkCycle runs every ROM of the assets uncapped, ticking the timers every DefaultIPF cycles.
+//If a program exits or fails, it's loaded again.
+func BenchmarkCycle(b *testing.B) {
+	roms, err := filepath.Glob("../assets/*.ch8")
+	if err != nil || len(roms) == 0 {
+		b.Fatal("no ROMs in the assets")
+	}
+	for _, rom := range roms {
+		rom := rom
+		b.Run(strings.TrimSuffix(filepath.Base(rom), ".ch8"), func(b *testing.B) {
+			load := func() *Chip8 {
+				c8, err := NewChip8(nil, Quirks{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err = c8.LoadROM(rom); err != nil {
+					b.Fatal(err)
+				}
+				return c8
+			}
+			c8 := load()
+			b.ResetTimer()
+			start := time.Now()
+			for k := 0; k < b.N; k++ {
+				if err := c8.Cycle(); err != nil || c8.IsClosed() {
+					c8 = load()
+				}
+				if k%DefaultIPF == 0 {
+					c8.TickTimers()
+				}
+			}
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "instr/s")
+		})
+	}
+}
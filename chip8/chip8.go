@@ -17,66 +17,64 @@ type Chip8 struct {
 	//It's an array of length 16 because there are 16 levels of nesting
 	sp byte //stack pointer, to keep track of what nesting level the program is at.
 
-	instructions map[uint16]func()
 	cOpcode      opcode              //current opcode
-	keyPressed   chan byte           //The chip8 has a hex keypad. The channel is acceded by the peripherals and represents the key that was just pressed
-	frameBuffer  monitor.FrameBuffer //The Chip8 has a monochromatic screen of 64x32 pixels. Each element of the FrameBuffer represents a pixel
-	//Each pixel can be on or off.
+	cInstruction *decoded            //current opcode decoded, with the instruction that executes it and its operands
+	keypad       Keypad              //The chip8 has a hex keypad, whose keys are pressed and released by the peripherals
+	frameBuffer  monitor.FrameBuffer //The Chip8 has a monochromatic screen of 64x32 pixels (128x64 in SUPER-CHIP high resolution).
+	//Each element of the FrameBuffer represents a pixel. Each pixel can be on or off.
 
 	delayTimer byte
 	soundTimer byte
 	MustDraw   bool
 	quit       bool
+
+	quirks        Quirks //Selects the interpretation of the ambiguous opcodes
+	waitingVBlank bool   //With the DisplayWait quirk, the chip8 stops after drawing until the next vertical blank
+	waitingKey    bool   //FX0A is waiting for a key to be pressed and released
+	waitedKey     int    //Key pressed while FX0A waits, -1 until a key is pressed
+
+	rplFlags  [NumberOfFlags]byte //The SUPER-CHIP can save registers into the RPL user flags of the HP48 calculator (FX75 and FX85)
+	flagsFile string              //File in which the RPL user flags are persisted, if it's empty they only live in memory
+
+	cycles     uint64     //Number of instructions executed
+	memoryHook MemoryHook //Called on every memory access of the instructions, it's used by the debugger
+	cycleHook  CycleHook  //Called after every instruction, it's used to trace the execution
+
+	planes       byte              //XO-CHIP planes in which the display instructions operate
+	audioPattern [PatternSize]byte //XO-CHIP plays these 128 bits, one after the other, while the sound timer is active
+	pitch        byte              //Sets the rate at which the bits of the audio pattern are played
+
+	randomSource RandomSource //Generates the random numbers of CXKK
+	randomState  uint64       //State of the random source
+	seed         int64        //Seed with which the random source started
 }
 
-func NewChip8(keyPressed chan byte) (*Chip8, error) {
+//NewChip8 instantiates a chip8 which reads its inputs from keypad and interprets the ambiguous opcodes following quirks.
+//If keypad is nil, no key is ever pressed. The built-in fonts of the CHIP-8 and the SUPER-CHIP are loaded into memory.
+//The random numbers are drawn from a PRNG with the seed 0, unless the options give another random source or seed.
+func NewChip8(keypad Keypad, quirks Quirks, options ...Option) (*Chip8, error) {
 	c8 := &Chip8{
 		memory:       [TotalMemory]byte{},
 		registers:    [NumberOfRegisters]byte{},
 		pc:           PCStartAddress,
 		stack:        [StackLevels]uint16{},
 		frameBuffer:  monitor.FrameBuffer{},
-		instructions: map[uint16]func(){},
-	}
-
-	c8.keyPressed = keyPressed
-	c8.instructions[0x00E0] = c8.I00E0
-	c8.instructions[0x00EE] = c8.I00EE
-	c8.instructions[0x1000] = c8.I1NNN
-	c8.instructions[0x2000] = c8.I2NNN
-	c8.instructions[0x3000] = c8.I3XKK
-	c8.instructions[0x4000] = c8.I4XKK
-	c8.instructions[0x5000] = c8.I5XY0
-	c8.instructions[0x6000] = c8.I6XKK
-	c8.instructions[0x7000] = c8.I7XKK
-	c8.instructions[0x8000] = c8.I8XY0
-	c8.instructions[0x8001] = c8.I8XY1
-	c8.instructions[0x8002] = c8.I8XY2
-	c8.instructions[0x8003] = c8.I8XY3
-	c8.instructions[0x8004] = c8.I8XY4
-	c8.instructions[0x8005] = c8.I8XY5
-	c8.instructions[0x8006] = c8.I8XY6
-	c8.instructions[0x8007] = c8.I8XY7
-	c8.instructions[0x800E] = c8.I8XYE
-	c8.instructions[0x9000] = c8.I9XY0
-	c8.instructions[0xA000] = c8.IANNN
-	c8.instructions[0xB000] = c8.IBNNN
-	c8.instructions[0xC000] = c8.ICXKK
-	c8.instructions[0xD000] = c8.IDXYN
-	c8.instructions[0xE09E] = c8.IEX9E
-	c8.instructions[0xE0A1] = c8.IEXA1
-	c8.instructions[0xF007] = c8.IFX07
-	c8.instructions[0xF00A] = c8.IFX0A
-	c8.instructions[0xF015] = c8.IFX15
-	c8.instructions[0xF018] = c8.IFX18
-	c8.instructions[0xF01E] = c8.IFX1E
-	c8.instructions[0xF029] = c8.IFX29
-	c8.instructions[0xF033] = c8.IFX33
-	c8.instructions[0xF055] = c8.IFX55
-	c8.instructions[0xF065] = c8.IFX65
-	c8.instructions[0x9001] = c8.I9XY1
-	c8.instructions[0x9002] = c8.I9XY2
+		quirks:       quirks,
+		planes:       1,
+		pitch:        DefaultPitch,
+		randomSource: PRNG{},
+	}
+
+	copy(c8.memory[FontsetStartAddress:], fontset[:])
+	copy(c8.memory[BigFontsetStartAddress:], bigFontset[:])
+	for _, option := range options {
+		option(c8)
+	}
 
+	c8.keypad = keypad
+	if c8.keypad == nil {
+		c8.keypad = &KeypadState{}
+	}
 	return c8, nil
 }
 
@@ -86,11 +84,34 @@ func (c8 *Chip8) LoadROM(filename string) error {
 	return loadFile(filename, MemoryForROM, PCStartAddress, &c8.memory)
 }
 
-//LoadFonts is called by an external app running chip8 to load a font file into memory
+//LoadFonts is called by an external app running chip8 to load a font file into memory, replacing the built-in 4x5 font
 func (c8 *Chip8) LoadFonts(filename string) error {
 	return loadFile(filename, MemoryForFonts, FontsetStartAddress, &c8.memory)
 }
 
+//LoadFlags is called by an external app running chip8 to choose the file in which the RPL user flags are persisted.
+//If the file already exists, the flags saved in it are loaded.
+func (c8 *Chip8) LoadFlags(filename string) error {
+	c8.flagsFile = filename
+	file, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	copy(c8.rplFlags[:], file)
+	return nil
+}
+
+//saveFlags persists the RPL user flags in the file given to LoadFlags
+func (c8 *Chip8) saveFlags() error {
+	if c8.flagsFile == "" {
+		return nil
+	}
+	return os.WriteFile(c8.flagsFile, c8.rplFlags[:], 0644)
+}
+
 //loadFile loads a file into the chip8 memory
 func loadFile(filename string, maxCapacity int, startAddress int, dst *[TotalMemory]byte) error {
 	file, err := os.ReadFile(filename)
@@ -110,28 +131,32 @@ func loadFile(filename string, maxCapacity int, startAddress int, dst *[TotalMem
 //fetchOpcode takes half of the opcode from the current position of the program counter, and the other half from program counter + 1
 //this is because an opcode has 2 bytes and every memory cell only has 1 byte,
 //then our program counter moves two cells forward
-func (c8 *Chip8) fetchOpcode() {
+func (c8 *Chip8) fetchOpcode() error {
+	if err := checkMemory(int(c8.pc), 2); err != nil {
+		return err
+	}
 	c8.cOpcode = opcode(uint16(c8.memory[c8.pc])<<8 | uint16(c8.memory[c8.pc+1]))
 	c8.pc += 2
+	return nil
 }
 
-//executeOpcode decodes the ID of the current opcode, and then execute the corresponding instruction
-func (c8 *Chip8) executeOpcode() {
-	id := c8.cOpcode.TakeOpcodeID()
-	if inst, ok := c8.instructions[id]; ok {
-		inst()
+//executeOpcode looks up the current opcode in the decode table, and then execute the corresponding instruction
+func (c8 *Chip8) executeOpcode() error {
+	c8.cInstruction = &decodeTable[c8.cOpcode]
+	if c8.cInstruction.execute == nil {
+		return ErrUnknownOpcode
 	}
-
+	return c8.cInstruction.execute(c8)
 }
 
-//countBackDelayTimer. The chip8 has a delay timer which decreases in every cycle
+//countBackDelayTimer. The chip8 has a delay timer which decreases 60 times per second
 func (c8 *Chip8) countBackDelayTimer() {
 	if c8.delayTimer != 0 {
 		c8.delayTimer--
 	}
 }
 
-//countBackSoundTimer. The chip8 has a sound timer which decreases in every cycle
+//countBackSoundTimer. The chip8 has a sound timer which decreases 60 times per second
 func (c8 *Chip8) countBackSoundTimer() {
 	if c8.soundTimer != 0 {
 		c8.soundTimer--
@@ -147,6 +172,11 @@ func (c8 *Chip8) MustBeep() bool {
 	}
 }
 
+//AudioPattern expose the XO-CHIP audio pattern buffer and its pitch so they can be played by the speaker
+func (c8 *Chip8) AudioPattern() ([PatternSize]byte, byte) {
+	return c8.audioPattern, c8.pitch
+}
+
 //GetFrameBuffer expose the FrameBuffer so it can be read by the monitor peripheral
 func (c8 *Chip8) GetFrameBuffer() monitor.FrameBuffer {
 	return c8.frameBuffer
@@ -161,13 +191,40 @@ func (c8 *Chip8) Close() {
 	c8.quit = true
 }
 
-//Cycle can be call for an external app which manages the chip8 with certain frequency
-//In every cycle we read, decode and execute the current opcode and we move the program counter by two, the we count back the sound timer and the delay timer
-func (c8 *Chip8) Cycle() {
-	c8.fetchOpcode()
-	c8.executeOpcode()
+//TickTimers must be called for an external app which manages the chip8 60 times per second, every time the screen is refreshed.
+//It counts back the delay timer and the sound timer, and with the DisplayWait quirk it resumes a chip8 that is waiting for the vertical blank after drawing a sprite.
+//The timers don't depend on the number of instructions executed per frame, so the speed of the chip8 doesn't change the timing of the programs.
+func (c8 *Chip8) TickTimers() {
 	c8.countBackSoundTimer()
 	c8.countBackDelayTimer()
+	c8.waitingVBlank = false
+}
+
+//Cycle can be call for an external app which manages the chip8 a number of times per frame
+//In every cycle we read, decode and execute the current opcode and we move the program counter by two.
+//If the chip8 is waiting for the vertical blank, nothing is executed.
+//If the program makes the instruction fail, Cycle returns a *Fault and the chip8 must not be cycled again.
+func (c8 *Chip8) Cycle() error {
+	if c8.waitingVBlank {
+		return nil
+	}
+	pc := c8.pc
+	var before Registers
+	if c8.cycleHook != nil {
+		before = c8.Registers()
+	}
+	err := c8.fetchOpcode()
+	if err == nil {
+		err = c8.executeOpcode()
+	}
+	if err != nil {
+		return &Fault{Err: err, PC: pc, Opcode: uint16(c8.cOpcode)}
+	}
+	if c8.cycleHook != nil {
+		c8.cycleHook(pc, uint16(c8.cOpcode), before)
+	}
+	c8.cycles++
+	return nil
 }
 
 //Dump is used in the debug mode of the app, it dumps the state of the chip8 into a StateChip8 an return it
@@ -186,6 +243,7 @@ func (c8 *Chip8) Dump() *state.StateChip8 {
 	s.SoundTimer = c8.soundTimer
 	s.MustDraw = c8.MustDraw
 	s.Quit = c8.quit
+	s.Seed = c8.seed
 	return s
 
 }
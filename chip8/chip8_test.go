@@ -1,9 +1,8 @@
 package chip8
 
 import (
-	"encoding/json"
+	"errors"
 	"github.com/NoetherianRing/Chip-8/config"
-	"github.com/NoetherianRing/Chip-8/state"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
@@ -26,84 +25,82 @@ func ObtainConfig() config.Config {
 	return cfg
 }
 
-func ObtainExpectedState(path string) []byte {
-	absPath, _ := filepath.Abs(path)
-
-	expectedResultROM, _ := ioutil.ReadFile(absPath)
-
-	return expectedResultROM
-}
-
 func TestChip8_LoadROM(t *testing.T) {
 	cfg := ObtainConfig()
-	expectedResultROM1 := ObtainExpectedState(cfg.Test.ExpectedStateROM1)
-	c8, err := NewChip8(nil)
+	c8, err := NewChip8(nil, Quirks{})
 
 	assert.NoError(t, err, "error in NewChip8")
 
-	var expected []state.StateChip8
-	_ = json.Unmarshal(expectedResultROM1, &expected)
-
 	absPath, _ := filepath.Abs(cfg.Test.ROM1)
 
 	err = c8.LoadROM(absPath)
 	assert.NoError(t, err, "error in LoadROM")
 
-	//	assert.Equal(t, expected[0].Memory[0x200:], c8.memory[0x200:], "ROM1")
-
+	rom, _ := ioutil.ReadFile(absPath)
+	assert.Equal(t, rom, c8.memory[PCStartAddress:PCStartAddress+len(rom)], "ROM1")
 }
 
 func TestChip8_LoadFonts(t *testing.T) {
 	cfg := ObtainConfig()
-	expectedResultROM1 := ObtainExpectedState(cfg.Test.ExpectedStateROM1)
-	c8, err := NewChip8(nil)
+	c8, err := NewChip8(nil, Quirks{})
 
 	assert.NoError(t, err, "error in NewChip8")
-	var expected []state.StateChip8
-	_ = json.Unmarshal(expectedResultROM1, &expected)
 	absPath, _ := filepath.Abs(cfg.Test.FONT)
 
 	err = c8.LoadFonts(absPath)
 	assert.NoError(t, err, "error in LoadFonts")
-	//assert.Equal(t, expected[0].Memory[:0x200], c8.memory[:0x200], "FONTS")
-}
-
-func TestChip8_Cycle(t *testing.T) {
-	cfg := ObtainConfig()
-	testCycle(t, cfg.Test.ExpectedStateROM1, cfg.Test.ROM1, cfg.Test.FONT)
-	testCycle(t, cfg.Test.ExpectedStateROM2, cfg.Test.ROM2, cfg.Test.FONT)
-	//	testCycle(t, cfg.Test.ExpectedStateROM3, cfg.Test.ROM3, cfg.Test.FONT)
 
+	fonts, _ := ioutil.ReadFile(absPath)
+	builtIn, _ := NewChip8(nil, Quirks{})
+	assert.Equal(t, fonts, builtIn.memory[FontsetStartAddress:FontsetStartAddress+len(fonts)], "the built-in font is the font file")
+	assert.Equal(t, fonts, c8.memory[FontsetStartAddress:FontsetStartAddress+len(fonts)], "FONTS")
 }
 
-func testCycle(t *testing.T, pathExpectedFile string, pathRom string, pathFont string) {
-	expectedResultROM := ObtainExpectedState(pathExpectedFile)
-	c8, err := NewChip8(nil)
-	assert.NoError(t, err, "error in NewChip8")
-
-	absPathROM, _ := filepath.Abs(pathRom)
-	err = c8.LoadROM(absPathROM)
-	assert.NoError(t, err, "error in LoadROM")
-
-	absPathFonts, _ := filepath.Abs(pathFont)
-	err = c8.LoadFonts(absPathFonts)
-	assert.NoError(t, err, "error in LoadFonts")
+func TestChip8_CycleErrors(t *testing.T) {
+	tests := []struct {
+		program []byte
+		err     error
+	}{
+		{[]byte{0x00, 0xEE}, ErrStackUnderflow},
+		{[]byte{0x22, 0x00}, ErrStackOverflow},
+		{[]byte{0x5A, 0xB1}, ErrUnknownOpcode},
+		{[]byte{0xAF, 0xFF, 0xF0, 0x00, 0xFF, 0xFE, 0xF2, 0x55}, ErrMemoryOutOfBounds},
+	}
 
-	var expected []state.StateChip8
-	_ = json.Unmarshal(expectedResultROM, &expected)
-
-	for i := 0; i < len(expected); i++ {
-		c8.Cycle()
-		//assert.Equal(t, expected[i].Memory, c8.memory, "ERROR IN MEMORY")
-		assert.Equal(t, expected[i].Registers, c8.registers, "ERROR IN REGISTERS")
-		assert.Equal(t, expected[i].Pc, c8.pc, "ERROR IN PC")
-		assert.Equal(t, expected[i].I, c8.i, "ERROR IN I")
-		assert.Equal(t, expected[i].Stack, c8.stack, "ERROR IN STACK")
-		assert.Equal(t, expected[i].Sp, c8.sp, "ERROR IN SP")
-		assert.Equal(t, expected[i].COpcode, uint16(c8.cOpcode), "ERROR IN COPCODE")
-		assert.Equal(t, expected[i].FrameBuffer, c8.frameBuffer, "ERROR IN FRAMEBUFFER")
-		assert.Equal(t, expected[i].DelayTimer, c8.delayTimer, "ERROR IN DELAYTIMER")
-		assert.Equal(t, expected[i].SoundTimer, c8.soundTimer, "ERROR IN SOUNDTIMER")
+	for _, test := range tests {
+		c8, _ := NewChip8(nil, Quirks{})
+		copy(c8.memory[PCStartAddress:], test.program)
+
+		var err error
+		for k := 0; k <= StackLevels && err == nil; k++ {
+			err = c8.Cycle()
+		}
+
+		assert.True(t, errors.Is(err, test.err), "expected %v, got %v", test.err, err)
+		var fault *Fault
+		if assert.True(t, errors.As(err, &fault), "Cycle must return a Fault") {
+			assert.Equal(t, uint16(c8.cOpcode), fault.Opcode, "opcode of the fault")
+			assert.Equal(t, c8.pc-2, fault.PC, "address of the fault")
+		}
+	}
+}
 
+func TestChip8_TickTimers(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	//LD V0, 3; LD DT, V0; LD ST, V0; JP 206
+	copy(c8.memory[PCStartAddress:], []byte{0x60, 0x03, 0xF0, 0x15, 0xF0, 0x18, 0x12, 0x06})
+	for k := 0; k < 100; k++ {
+		assert.NoError(t, c8.Cycle(), "error in Cycle")
 	}
+	assert.Equal(t, byte(3), c8.delayTimer, "the timers don't depend on the cycles")
+	assert.Equal(t, byte(3), c8.soundTimer, "the timers don't depend on the cycles")
+
+	c8.TickTimers()
+	assert.Equal(t, byte(2), c8.delayTimer, "")
+	assert.Equal(t, byte(2), c8.soundTimer, "")
+	c8.TickTimers()
+	c8.TickTimers()
+	c8.TickTimers()
+	assert.Equal(t, byte(0), c8.delayTimer, "stops at 0")
+	assert.False(t, c8.MustBeep(), "")
 }
@@ -0,0 +1,90 @@
+package chip8
+
+//decoded is an opcode decoded before the program runs: the instruction that executes it and its operands,
+//so the instructions don't decode the opcode every time they read an operand
+type decoded struct {
+	execute func(c8 *Chip8) error //nil if the opcode is unknown
+	nnn     uint16
+	n       uint16
+	kk      uint8
+	x       uint8
+	y       uint8
+}
+
+//decodeTable has every opcode decoded, it's indexed by the opcode
+var decodeTable [0x10000]decoded
+
+//instructionSet maps the IDs of the opcodes given by TakeOpcodeID to their instructions
+var instructionSet = map[uint16]func(c8 *Chip8) error{
+	0x00E0: (*Chip8).I00E0,
+	0x00EE: (*Chip8).I00EE,
+	0x1000: (*Chip8).I1NNN,
+	0x2000: (*Chip8).I2NNN,
+	0x3000: (*Chip8).I3XKK,
+	0x4000: (*Chip8).I4XKK,
+	0x5000: (*Chip8).I5XY0,
+	0x6000: (*Chip8).I6XKK,
+	0x7000: (*Chip8).I7XKK,
+	0x8000: (*Chip8).I8XY0,
+	0x8001: (*Chip8).I8XY1,
+	0x8002: (*Chip8).I8XY2,
+	0x8003: (*Chip8).I8XY3,
+	0x8004: (*Chip8).I8XY4,
+	0x8005: (*Chip8).I8XY5,
+	0x8006: (*Chip8).I8XY6,
+	0x8007: (*Chip8).I8XY7,
+	0x800E: (*Chip8).I8XYE,
+	0x9000: (*Chip8).I9XY0,
+	0xA000: (*Chip8).IANNN,
+	0xB000: (*Chip8).IBNNN,
+	0xC000: (*Chip8).ICXKK,
+	0xD000: (*Chip8).IDXYN,
+	0xE09E: (*Chip8).IEX9E,
+	0xE0A1: (*Chip8).IEXA1,
+	0xF007: (*Chip8).IFX07,
+	0xF00A: (*Chip8).IFX0A,
+	0xF015: (*Chip8).IFX15,
+	0xF018: (*Chip8).IFX18,
+	0xF01E: (*Chip8).IFX1E,
+	0xF029: (*Chip8).IFX29,
+	0xF033: (*Chip8).IFX33,
+	0xF055: (*Chip8).IFX55,
+	0xF065: (*Chip8).IFX65,
+	0x9001: (*Chip8).I9XY1,
+	0x9002: (*Chip8).I9XY2,
+
+	//SUPER-CHIP instructions
+	0x00C0: (*Chip8).I00CN,
+	0x00FB: (*Chip8).I00FB,
+	0x00FC: (*Chip8).I00FC,
+	0x00FD: (*Chip8).I00FD,
+	0x00FE: (*Chip8).I00FE,
+	0x00FF: (*Chip8).I00FF,
+	0xF030: (*Chip8).IFX30,
+	0xF075: (*Chip8).IFX75,
+	0xF085: (*Chip8).IFX85,
+
+	//XO-CHIP instructions
+	0x00D0: (*Chip8).I00DN,
+	0x5002: (*Chip8).I5XY2,
+	0x5003: (*Chip8).I5XY3,
+	0xF000: (*Chip8).IF000,
+	0xF001: (*Chip8).IFN01,
+	0xF002: (*Chip8).IF002,
+	0xF03A: (*Chip8).IFX3A,
+}
+
+//init decodes every opcode into the decode table
+func init() {
+	for k := range decodeTable {
+		oc := opcode(k)
+		decodeTable[k] = decoded{
+			execute: instructionSet[oc.TakeOpcodeID()],
+			nnn:     oc.NNN(),
+			n:       oc.N(),
+			kk:      oc.KK(),
+			x:       oc.X(),
+			y:       oc.Y(),
+		}
+	}
+}
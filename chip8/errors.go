@@ -0,0 +1,38 @@
+package chip8
+
+import (
+	"errors"
+	"fmt"
+)
+
+//These are the errors that a program can cause when the chip8 executes it. Cycle returns them wrapped in a Fault
+var (
+	ErrUnknownOpcode     = errors.New("unknown opcode")
+	ErrStackOverflow     = errors.New("stack overflow")
+	ErrStackUnderflow    = errors.New("stack underflow")
+	ErrMemoryOutOfBounds = errors.New("memory access out of bounds")
+)
+
+//Fault is the error returned by Cycle when an instruction fails. It keeps the address and the opcode of the instruction,
+//and wraps the cause, so it can be checked with errors.Is (for example errors.Is(err, ErrStackOverflow))
+type Fault struct {
+	Err    error
+	PC     uint16 //Address of the instruction that failed
+	Opcode uint16
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("%v (opcode %04X at address %03X)", f.Err, f.Opcode, f.PC)
+}
+
+func (f *Fault) Unwrap() error {
+	return f.Err
+}
+
+//checkMemory returns ErrMemoryOutOfBounds if the n bytes starting at addr are not inside the address space of the chip8
+func checkMemory(addr int, n int) error {
+	if addr < 0 || addr+n > AddressSpace {
+		return ErrMemoryOutOfBounds
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package chip8
+
+//Registers is a copy of the registers of the chip8, exposed for the tools that inspect it while it runs, like the debugger
+type Registers struct {
+	V          [NumberOfRegisters]byte
+	I          uint16
+	PC         uint16
+	SP         byte
+	Stack      [StackLevels]uint16
+	DelayTimer byte
+	SoundTimer byte
+}
+
+//MemoryHook is called every time an instruction reads (write = false) or writes (write = true) n bytes of memory starting at addr.
+//Fetching the opcodes doesn't call it.
+type MemoryHook func(addr uint16, n int, write bool)
+
+//CycleHook is called by Cycle before counting every instruction it executes,
+//with the address and the opcode of the instruction and the registers as they were before executing it.
+type CycleHook func(pc uint16, opcode uint16, before Registers)
+
+//Registers returns a copy of the registers of the chip8
+func (c8 *Chip8) Registers() Registers {
+	return Registers{
+		V:          c8.registers,
+		I:          c8.i,
+		PC:         c8.pc,
+		SP:         c8.sp,
+		Stack:      c8.stack,
+		DelayTimer: c8.delayTimer,
+		SoundTimer: c8.soundTimer,
+	}
+}
+
+//ReadMemory returns the byte stored at addr
+func (c8 *Chip8) ReadMemory(addr uint16) byte {
+	return c8.memory[addr]
+}
+
+//WriteMemory stores b at addr. It's used by the tools which set up a program before running it, such as the test command.
+func (c8 *Chip8) WriteMemory(addr uint16, b byte) {
+	c8.memory[addr] = b
+}
+
+//PeekOpcode returns the opcode that the next Cycle is going to execute
+func (c8 *Chip8) PeekOpcode() uint16 {
+	return uint16(c8.memory[c8.pc])<<8 | uint16(c8.memory[int(c8.pc)+1])
+}
+
+//Cycles returns the number of instructions executed since the chip8 was created
+func (c8 *Chip8) Cycles() uint64 {
+	return c8.cycles
+}
+
+//SetMemoryHook sets the function called on every memory access of the instructions, nil removes it
+func (c8 *Chip8) SetMemoryHook(hook MemoryHook) {
+	c8.memoryHook = hook
+}
+
+//SetCycleHook sets the function called after every instruction executed, nil removes it. It's used to trace the execution.
+func (c8 *Chip8) SetCycleHook(hook CycleHook) {
+	c8.cycleHook = hook
+}
+
+//accessMemory checks that the n bytes starting at addr are inside the address space before an instruction accesses them,
+//and reports the access to the memory hook
+func (c8 *Chip8) accessMemory(addr int, n int, write bool) error {
+	if err := checkMemory(addr, n); err != nil {
+		return err
+	}
+	if c8.memoryHook != nil {
+		c8.memoryHook(uint16(addr), n, write)
+	}
+	return nil
+}
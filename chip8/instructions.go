@@ -1,332 +1,622 @@
 package chip8
 
 import (
-	"math/rand"
+	"github.com/NoetherianRing/Chip-8/monitor"
 )
 
 //I00E0 clears the myMonitor
-func (c8 *Chip8) I00E0() { //CLS
-	c8.frameBuffer = [64 * 32]byte{}
+func (c8 *Chip8) I00E0() error { //CLS
+	c8.frameBuffer.ClearPlanes(c8.planes)
 	c8.MustDraw = true
+	return nil
 }
 
 //I00EE returns from a subroutine
-func (c8 *Chip8) I00EE() { //RET
+func (c8 *Chip8) I00EE() error { //RET
+	if c8.sp == 0 {
+		return ErrStackUnderflow
+	}
 	c8.sp--
 	c8.pc = c8.stack[c8.sp]
+	return nil
 }
 
 //I1NNN Jumps to location nnn
-func (c8 *Chip8) I1NNN() { //JP (ADDR)
-	addr := c8.cOpcode.NNN()
+func (c8 *Chip8) I1NNN() error { //JP (ADDR)
+	addr := c8.cInstruction.nnn
 	c8.pc = addr
+	return nil
 }
 
 // I2NNN CALL (ADDR)
-func (c8 *Chip8) I2NNN() {
-	addr := c8.cOpcode.NNN()
+func (c8 *Chip8) I2NNN() error {
+	if int(c8.sp) >= StackLevels {
+		return ErrStackOverflow
+	}
+	addr := c8.cInstruction.nnn
 	c8.stack[c8.sp] = c8.pc
 	c8.sp++
 	c8.pc = addr
+	return nil
+}
+
+//skipNextInstruction moves the program counter over the next instruction.
+//F000 NNNN is the only instruction of 4 bytes (XO-CHIP), so it's skipped entirely
+func (c8 *Chip8) skipNextInstruction() {
+	if checkMemory(int(c8.pc), 2) == nil && uint16(c8.memory[c8.pc])<<8|uint16(c8.memory[c8.pc+1]) == 0xF000 {
+		c8.pc += 2
+	}
+	c8.pc += 2
 }
 
 //I3XKK
 //Skip next instruction if Vx = kk
-func (c8 *Chip8) I3XKK() { //SE (VX, BYTE)
-	vx := c8.registers[c8.cOpcode.X()]
-	_byte := c8.cOpcode.KK()
+func (c8 *Chip8) I3XKK() error { //SE (VX, BYTE)
+	vx := c8.registers[c8.cInstruction.x]
+	_byte := c8.cInstruction.kk
 	if vx == _byte {
-		c8.pc += 2
+		c8.skipNextInstruction()
 	}
+	return nil
 }
 
 //I4XKK
 //Skip next instruction if Vx != kk
-func (c8 *Chip8) I4XKK() { //SNE (VX, BYTE)
-	vx := c8.registers[c8.cOpcode.X()]
-	_byte := c8.cOpcode.KK()
+func (c8 *Chip8) I4XKK() error { //SNE (VX, BYTE)
+	vx := c8.registers[c8.cInstruction.x]
+	_byte := c8.cInstruction.kk
 	if vx != _byte {
-		c8.pc += 2
+		c8.skipNextInstruction()
 	}
+	return nil
 }
 
 //I5XY0
 //Skip next instruction if vx = vy
-func (c8 *Chip8) I5XY0() { //SE (VX, VY)
-	vx := c8.registers[c8.cOpcode.X()]
-	vy := c8.registers[c8.cOpcode.Y()]
+func (c8 *Chip8) I5XY0() error { //SE (VX, VY)
+	vx := c8.registers[c8.cInstruction.x]
+	vy := c8.registers[c8.cInstruction.y]
 
 	if vx == vy {
-		c8.pc += 2
+		c8.skipNextInstruction()
 	}
+	return nil
 }
 
 //I6XKK Set vx = kk
-func (c8 *Chip8) I6XKK() { //LV (VX, BYTE)
-	_byte := c8.cOpcode.KK()
-	c8.registers[c8.cOpcode.X()] = _byte
+func (c8 *Chip8) I6XKK() error { //LV (VX, BYTE)
+	_byte := c8.cInstruction.kk
+	c8.registers[c8.cInstruction.x] = _byte
+	return nil
 }
 
 //I7XKK ADD (VX, BYTE)
-func (c8 *Chip8) I7XKK() {
-	_byte := c8.cOpcode.KK()
-	c8.registers[c8.cOpcode.X()] += _byte
+func (c8 *Chip8) I7XKK() error {
+	_byte := c8.cInstruction.kk
+	c8.registers[c8.cInstruction.x] += _byte
+	return nil
 }
 
 //I8XY0 LD (VX, VY) Set Vx = Vy
-func (c8 *Chip8) I8XY0() {
-	c8.registers[c8.cOpcode.X()] = c8.registers[c8.cOpcode.Y()]
+func (c8 *Chip8) I8XY0() error {
+	c8.registers[c8.cInstruction.x] = c8.registers[c8.cInstruction.y]
+	return nil
 }
 
 //I8XY1 OR(VX, VY)
-func (c8 *Chip8) I8XY1() {
-	c8.registers[c8.cOpcode.X()] |= c8.registers[c8.cOpcode.Y()]
+func (c8 *Chip8) I8XY1() error {
+	c8.registers[c8.cInstruction.x] |= c8.registers[c8.cInstruction.y]
+	c8.resetVF()
+	return nil
 }
 
 //I8XY2 AND (VX, VY)
-func (c8 *Chip8) I8XY2() {
-	c8.registers[c8.cOpcode.X()] &= c8.registers[c8.cOpcode.Y()]
+func (c8 *Chip8) I8XY2() error {
+	c8.registers[c8.cInstruction.x] &= c8.registers[c8.cInstruction.y]
+	c8.resetVF()
+	return nil
 }
 
 //I8XY3 XOR (VX, VY)
-func (c8 *Chip8) I8XY3() {
-	x := c8.cOpcode.X()
-	y := c8.cOpcode.Y()
+func (c8 *Chip8) I8XY3() error {
+	x := c8.cInstruction.x
+	y := c8.cInstruction.y
 	c8.registers[x] ^= c8.registers[y]
+	c8.resetVF()
+	return nil
+}
 
+//resetVF sets VF to 0 after a logic operation when the VFReset quirk is on, as the COSMAC VIP did
+func (c8 *Chip8) resetVF() {
+	if c8.quirks.VFReset {
+		c8.registers[0xF] = 0
+	}
 }
 
 //I8XY4
 //The values of Vx and Vy are added together.
 //If the result is greater than 8 bits (i.e., > 255,) VF is set to 1, otherwise 0. Only the lowest 8 bits of the result are kept, and stored in Vx.
-func (c8 *Chip8) I8XY4() { //ADD (VS, VY)
-	sum := c8.registers[c8.cOpcode.X()] + c8.registers[c8.cOpcode.Y()]
-	c8.registers[c8.cOpcode.X()] = sum & 0x00FF
+//VF is set after Vx, so it holds the carry even if x is F.
+func (c8 *Chip8) I8XY4() error { //ADD (VS, VY)
+	sum := uint16(c8.registers[c8.cInstruction.x]) + uint16(c8.registers[c8.cInstruction.y])
+	c8.registers[c8.cInstruction.x] = byte(sum & 0x00FF)
 
 	if sum > 255 {
 		c8.registers[0xF] = 1
 	} else {
 		c8.registers[0xF] = 0
 	}
+	return nil
 }
 
-//f Vx > Vy, then VF is set to 1, otherwise 0. Then Vy is subtracted from Vx, and the results stored in Vx.
-func (c8 *Chip8) I8XY5() { //SUB (VX, VY)
-	x := c8.cOpcode.X()
-	y := c8.cOpcode.Y()
-	if c8.registers[x] > c8.registers[y] {
+//I8XY5
+//Vy is subtracted from Vx, and the results stored in Vx. If Vx >= Vy, there isn't a borrow and VF is set to 1, otherwise 0.
+//VF is set after Vx, so it holds the borrow even if x is F.
+func (c8 *Chip8) I8XY5() error { //SUB (VX, VY)
+	x := c8.cInstruction.x
+	y := c8.cInstruction.y
+	noBorrow := c8.registers[x] >= c8.registers[y]
+	c8.registers[x] -= c8.registers[y]
+
+	if noBorrow {
 		c8.registers[0xF] = 1
 	} else {
 		c8.registers[0xF] = 0
 	}
-
-	c8.registers[c8.cOpcode.X()] -= c8.registers[c8.cOpcode.Y()]
+	return nil
 }
 
 //I8XY6
 //If the least-significant bit of Vx is 1, then VF is set to 1, otherwise 0. Then Vx is divided by 2.
-func (c8 *Chip8) I8XY6() { //SHR (VX, {, VY})
-	c8.registers[0xF] = c8.registers[c8.cOpcode.X()] & 0x1 // 0x1: 00000001
-	c8.registers[c8.cOpcode.X()] >>= 1
+//With the ShiftVY quirk, Vy is the one that is divided by 2 and the result is stored in Vx.
+func (c8 *Chip8) I8XY6() error { //SHR (VX, {, VY})
+	value := c8.shiftSource()
+	c8.registers[c8.cInstruction.x] = value >> 1
+	c8.registers[0xF] = value & 0x1 // 0x1: 00000001
+	return nil
 }
 
 //I8XY7
-//If Vy > Vx, then VF is set to 1, otherwise 0. Then Vx is subtracted from Vy, and the results stored in Vx.
-func (c8 *Chip8) I8XY7() { //SUBN (VX, VY)
-	if c8.registers[c8.cOpcode.Y()] > c8.registers[c8.cOpcode.X()] {
+//Vx is subtracted from Vy, and the results stored in Vx. If Vy >= Vx, there isn't a borrow and VF is set to 1, otherwise 0.
+//VF is set after Vx, so it holds the borrow even if x is F.
+func (c8 *Chip8) I8XY7() error { //SUBN (VX, VY)
+	x := c8.cInstruction.x
+	y := c8.cInstruction.y
+	noBorrow := c8.registers[y] >= c8.registers[x]
+	c8.registers[x] = c8.registers[y] - c8.registers[x]
+
+	if noBorrow {
 		c8.registers[0xF] = 1
 	} else {
 		c8.registers[0xF] = 0
 	}
-
-	c8.registers[c8.cOpcode.X()] = c8.registers[c8.cOpcode.Y()] - c8.registers[c8.cOpcode.X()]
-
+	return nil
 }
 
 //I8XYE
 //If the most-significant bit of Vx is 1, then VF is set to 1, otherwise to 0. Then Vx is multiplied by 2.
-func (c8 *Chip8) I8XYE() { //SHL (Vx {, Vy})
-	c8.registers[0xF] = c8.registers[c8.cOpcode.X()] & 0x80 //0x80: 10000000
-	c8.registers[c8.cOpcode.X()] <<= 1
+//With the ShiftVY quirk, Vy is the one that is multiplied by 2 and the result is stored in Vx.
+func (c8 *Chip8) I8XYE() error { //SHL (Vx {, Vy})
+	value := c8.shiftSource()
+	c8.registers[c8.cInstruction.x] = value << 1
+	c8.registers[0xF] = (value & 0x80) >> 7 //0x80: 10000000
+	return nil
+}
+
+//shiftSource returns the register that 8XY6 and 8XYE shift, which depends on the ShiftVY quirk
+func (c8 *Chip8) shiftSource() byte {
+	if c8.quirks.ShiftVY {
+		return c8.registers[c8.cInstruction.y]
+	}
+	return c8.registers[c8.cInstruction.x]
 }
 
 //I9XY0
 //Skip next instruction if Vx != Vy.
-func (c8 *Chip8) I9XY0() { //SNE (Vx, Vy)
-	if c8.registers[c8.cOpcode.X()] != c8.registers[c8.cOpcode.Y()] {
-		c8.pc += 2
+func (c8 *Chip8) I9XY0() error { //SNE (Vx, Vy)
+	if c8.registers[c8.cInstruction.x] != c8.registers[c8.cInstruction.y] {
+		c8.skipNextInstruction()
 	}
+	return nil
 }
 
 //IANNN Set I = NNN
-func (c8 *Chip8) IANNN() { // LD I, addr
-	c8.i = c8.cOpcode.NNN()
+func (c8 *Chip8) IANNN() error { // LD I, addr
+	c8.i = c8.cInstruction.nnn
+	return nil
 }
 
 //IBNNN Jump to location nnn + V0.
-func (c8 *Chip8) IBNNN() { // JP V0, addr
-	c8.pc = uint16(c8.registers[0]) + c8.cOpcode.NNN()
+//With the JumpVX quirk, the instruction is read as BXNN and jumps to location xnn + Vx.
+func (c8 *Chip8) IBNNN() error { // JP V0, addr
+	offset := c8.registers[0]
+	if c8.quirks.JumpVX {
+		offset = c8.registers[c8.cInstruction.x]
+	}
+	c8.pc = uint16(offset) + c8.cInstruction.nnn
+	return nil
 }
 
-//ICXKK Set Vx = random byte AND kk.
-func (c8 *Chip8) ICXKK() { // RND Vx, byte
-	c8.registers[c8.cOpcode.X()] = uint8(rand.Intn(256)) & c8.cOpcode.KK()
+//ICXKK Set Vx = random byte AND kk. The random bytes are drawn from the random source of the chip8.
+func (c8 *Chip8) ICXKK() error { // RND Vx, byte
+	c8.registers[c8.cInstruction.x] = c8.randomByte() & c8.cInstruction.kk
+	return nil
 }
 
 //IDXYN displays n-byte sprite starting at memory location I at (Vx, Vy) and set VF = collision.
-func (c8 *Chip8) IDXYN() { // DRW (Vx, Vy, hSprite)
-	vx := c8.registers[c8.cOpcode.X()]
-	vy := c8.registers[c8.cOpcode.Y()]
-	//If a sprite is attempting to draw outside the bounds of the screen,
-	//it wraps around to the other side, that's why we do x0 = vx % width, y0 = vy % height.
-	x0 := int(vx % WidthScreen)
-	y0 := int(vy % HeightScreen)
+//If n = 0 (DXY0), it displays a 16x16 sprite of 32 bytes, as in the SUPER-CHIP
+//If both XO-CHIP planes are selected, the sprite for the second plane is read right after the sprite for the first one
+func (c8 *Chip8) IDXYN() error { // DRW (Vx, Vy, hSprite)
+	vx := c8.registers[c8.cInstruction.x]
+	vy := c8.registers[c8.cInstruction.y]
+	//If the coordinates of a sprite are outside the bounds of the screen,
+	//they wrap around to the other side, that's why we do x0 = vx % width, y0 = vy % height.
+	x0 := int(vx) % c8.frameBuffer.Width()
+	y0 := int(vy) % c8.frameBuffer.Height()
 
 	//Each sprite has a width of 8 pixels (represented by a byte), and a height N
-	hSprite := int(c8.cOpcode.N())
+	//or a width of 16 pixels (represented by two bytes) and a height of 16 if N = 0
+	hSprite, wSprite := int(c8.cInstruction.n), 8
+	if hSprite == 0 {
+		hSprite, wSprite = 16, 16
+	}
 	i := int(c8.i)
-	var _byte byte
-	var bit byte
+	spriteSize := hSprite * wSprite / 8
+	if c8.planes == monitor.AllPlanes {
+		spriteSize *= 2
+	}
+	if err := c8.accessMemory(i, spriteSize, false); err != nil {
+		return err
+	}
 	c8.registers[0xF] = 0
 
+	for plane := byte(1); plane <= monitor.AllPlanes; plane <<= 1 {
+		if c8.planes&plane == 0 {
+			continue
+		}
+		if c8.drawSprite(x0, y0, i, wSprite, hSprite, plane) {
+			c8.registers[0xF] = 1
+		}
+		i += hSprite * wSprite / 8
+	}
+
+	c8.MustDraw = true
+	c8.waitingVBlank = c8.quirks.DisplayWait
+	return nil
+}
+
+//drawSprite draws in the given plane the sprite of wSprite x hSprite pixels stored in memory from i, and returns if there was a collision
+func (c8 *Chip8) drawSprite(x0, y0, i, wSprite, hSprite int, plane byte) bool {
+	width, height := c8.frameBuffer.Width(), c8.frameBuffer.Height()
+	bytesPerRow := wSprite / 8
+	collision := false
+	var row uint16
+	var bit uint16
+
 	for y := 0; y < hSprite; y++ {
-		_byte = c8.memory[i+y]
+		row = uint16(c8.memory[i+y*bytesPerRow]) << 8
+		if bytesPerRow == 2 {
+			row |= uint16(c8.memory[i+y*bytesPerRow+1])
+		}
 
-		//Every bit of each i-byte (0<i<N) of the sprite represents a pixel on the screen which can be ON or OFF.
-		//if the sprite pixel is ON, then we check if that pixel is already ON in the FrameBuffer. In that case we set Vf = 1 to indicate a collision
+		//Every bit of each row of the sprite represents a pixel on the screen which can be ON or OFF.
+		//if the sprite pixel is ON, then we check if that pixel is already ON in the FrameBuffer. In that case there is a collision
 		//then we use a XOR operation, so if the sprite pixel is ON and the display pixel is ON, we set the display pixel to OFF
 		//and if the sprite pixel is ON and the display pixel is OFF, we set the display pixel to ON
-		for x := 0; x < 8; x++ {
-			bit = _byte & (0x80 >> x)
+		//The pixels of the sprite that fall outside the screen are clipped, unless the WrapSprites quirk is on.
+		for x := 0; x < wSprite; x++ {
+			bit = row & (0x8000 >> x)
 			if bit != 0 {
+				px, py := x0+x, y0+y
+				if c8.quirks.WrapSprites {
+					px, py = px%width, py%height
+				}
 
-				if c8.frameBuffer.CheckOverlap(x0+x, y0+y) {
+				if c8.frameBuffer.CheckOverlap(px, py) {
 					continue
 				} else {
-					cellFrameBuffer := c8.frameBuffer.Get(x0+x, y0+y)
-					if *cellFrameBuffer == 1 {
-						c8.registers[0xF] = 1
+					cellFrameBuffer := c8.frameBuffer.Get(px, py)
+					if *cellFrameBuffer&plane != 0 {
+						collision = true
 					}
 
-					*cellFrameBuffer ^= 1
+					*cellFrameBuffer ^= plane
 				}
 
 			}
 		}
 	}
-
-	c8.MustDraw = true
-
+	return collision
 }
 
 //IEX9E Skip next instruction if key with the value of Vx is pressed.
-func (c8 *Chip8) IEX9E() { //SKP(VX)
-	key := c8.registers[c8.cOpcode.X()]
-	select {
-	case keyPressed := <-c8.keyPressed:
-		if keyPressed == key {
-			c8.pc += 2
-		}
-		return
-	default:
-		return
+func (c8 *Chip8) IEX9E() error { //SKP(VX)
+	if c8.keypad.IsPressed(c8.registers[c8.cInstruction.x] & 0xF) {
+		c8.skipNextInstruction()
 	}
-
+	return nil
 }
 
 //IEXA1 Skip next instruction if key with the value of Vx is not pressed.
-func (c8 *Chip8) IEXA1() { //SKP(VX)
-	key := c8.registers[c8.cOpcode.X()]
-	select {
-	case keyPressed := <-c8.keyPressed:
-		if keyPressed == key {
-			return
-		}
-		c8.pc += 2
-	default:
-		c8.pc += 2
-
+func (c8 *Chip8) IEXA1() error { //SKNP(VX)
+	if !c8.keypad.IsPressed(c8.registers[c8.cInstruction.x] & 0xF) {
+		c8.skipNextInstruction()
 	}
-
+	return nil
 }
 
 //IFX07 Set Vx = delay timer value.
-func (c8 *Chip8) IFX07() { //LD (Vx, DT)
-	c8.registers[c8.cOpcode.X()] = c8.delayTimer
+func (c8 *Chip8) IFX07() error { //LD (Vx, DT)
+	c8.registers[c8.cInstruction.x] = c8.delayTimer
+	return nil
 }
 
 //IFX0A Wait for a key press, store the value of the key in Vx.
-func (c8 *Chip8) IFX0A() { //LD (Vx, K)
-	for {
-		select {
-		case key := <-c8.keyPressed:
-			if key <= NumberOfKeys || key == AsciiEscape {
-				c8.registers[c8.cOpcode.X()] = key
-				return
+//As in the COSMAC VIP, the key is stored when it's released after being pressed.
+//The chip8 doesn't block while it waits: the instruction is executed again in every cycle until the key is released.
+func (c8 *Chip8) IFX0A() error { //LD (Vx, K)
+	if !c8.waitingKey {
+		c8.waitingKey = true
+		c8.waitedKey = -1
+	}
+	if c8.waitedKey < 0 {
+		for key := byte(0); key < NumberOfKeys; key++ {
+			if c8.keypad.IsPressed(key) {
+				c8.waitedKey = int(key)
+				break
 			}
-
 		}
-
+	} else if !c8.keypad.IsPressed(byte(c8.waitedKey)) {
+		c8.registers[c8.cInstruction.x] = byte(c8.waitedKey)
+		c8.waitingKey = false
+		return nil
 	}
-
+	c8.pc -= 2
+	return nil
 }
 
 //IFX15 Set delay timer = Vx
-func (c8 *Chip8) IFX15() { //LD (DT, Vx)
-	c8.delayTimer = c8.registers[c8.cOpcode.X()]
+func (c8 *Chip8) IFX15() error { //LD (DT, Vx)
+	c8.delayTimer = c8.registers[c8.cInstruction.x]
+	return nil
 }
 
 //IFX18 Set sound timer = Vx
-func (c8 *Chip8) IFX18() { //LD (ST, Vx)
-	c8.soundTimer = c8.registers[c8.cOpcode.X()]
+func (c8 *Chip8) IFX18() error { //LD (ST, Vx)
+	c8.soundTimer = c8.registers[c8.cInstruction.x]
+	return nil
 }
 
 //IFX1E Set I = I + Vx
-func (c8 *Chip8) IFX1E() { //ADD (I, Vx)
-	c8.i += uint16(c8.registers[c8.cOpcode.X()])
+func (c8 *Chip8) IFX1E() error { //ADD (I, Vx)
+	c8.i += uint16(c8.registers[c8.cInstruction.x])
+	return nil
 }
 
 //IFX29 Set I = location of sprite for digit Vx.
-func (c8 *Chip8) IFX29() { //LD (F, Vx)
-	vx := c8.registers[c8.cOpcode.X()] // c between 0 and F
+func (c8 *Chip8) IFX29() error { //LD (F, Vx)
+	vx := c8.registers[c8.cInstruction.x] // c between 0 and F
 	c8.i = FontsetStartAddress + uint16(FontSize*vx)
+	return nil
 }
 
 //IFX33 Store BCD representation of Vx in memory locations I, I+1, and I+2.
 //The interpreter takes the decimal value of Vx,
 //and places the hundreds digit in memory at location in I, the tens digit at location I+1, and the ones digit at location I+2.
-func (c8 *Chip8) IFX33() { //LD (B, Vx)
-	vx := c8.registers[c8.cOpcode.X()]
+func (c8 *Chip8) IFX33() error { //LD (B, Vx)
+	if err := c8.accessMemory(int(c8.i), 3, true); err != nil {
+		return err
+	}
+	vx := c8.registers[c8.cInstruction.x]
 	c8.memory[c8.i+2] = vx % 10
 	c8.memory[c8.i+1] = (vx / 10) % 10
 	c8.memory[c8.i] = (vx / 100) % 10
+	return nil
 }
 
 //IFX55 Stores registers V0 through Vx in memory starting at location I.
-func (c8 *Chip8) IFX55() { //LD (I,Vx)
-	for k := 0; k <= int(c8.cOpcode.X()); k++ {
+func (c8 *Chip8) IFX55() error { //LD (I,Vx)
+	if err := c8.accessMemory(int(c8.i), int(c8.cInstruction.x)+1, true); err != nil {
+		return err
+	}
+	for k := 0; k <= int(c8.cInstruction.x); k++ {
 		c8.memory[c8.i+uint16(k)] = c8.registers[k]
 	}
+	c8.incrementIndex()
+	return nil
 }
 
 //IFX65 Reads registers V0 through Vx from memory starting at location I.
-func (c8 *Chip8) IFX65() { //LD (Vx, I)
-	for k := 0; k <= int(c8.cOpcode.X()); k++ {
+func (c8 *Chip8) IFX65() error { //LD (Vx, I)
+	if err := c8.accessMemory(int(c8.i), int(c8.cInstruction.x)+1, false); err != nil {
+		return err
+	}
+	for k := 0; k <= int(c8.cInstruction.x); k++ {
 		c8.registers[k] = c8.memory[c8.i+uint16(k)]
 	}
+	c8.incrementIndex()
+	return nil
+}
+
+//incrementIndex moves I after FX55 and FX65 as indicated by the IndexIncrement quirk
+func (c8 *Chip8) incrementIndex() {
+	switch c8.quirks.IndexIncrement {
+	case IncrementX:
+		c8.i += uint16(c8.cInstruction.x)
+	case IncrementXPlus1:
+		c8.i += uint16(c8.cInstruction.x) + 1
+	}
 }
 
 //I9XY1 save vx in the first 8 bits of i and vy in the last 8.
 //This instruction is part of our extended instruction set, required for the c8-compiler
-func (c8 *Chip8) I9XY1() {
-	c8.i = uint16(c8.registers[c8.cOpcode.X()])<<8 | uint16(c8.registers[c8.cOpcode.Y()])
-
+func (c8 *Chip8) I9XY1() error {
+	c8.i = uint16(c8.registers[c8.cInstruction.x])<<8 | uint16(c8.registers[c8.cInstruction.y])
+	return nil
 }
 
 //I9XY2 save the first 8 bits of i in vx, and the last 8 bits in vy
 //This instruction is part of our extended instruction set, required for the c8-compiler
-func (c8 *Chip8) I9XY2() {
-	c8.registers[c8.cOpcode.X()] = byte(c8.i >> 8)
-	c8.registers[c8.cOpcode.Y()] = byte(c8.i)
+func (c8 *Chip8) I9XY2() error {
+	c8.registers[c8.cInstruction.x] = byte(c8.i >> 8)
+	c8.registers[c8.cInstruction.y] = byte(c8.i)
+	return nil
+}
+
+//I00CN scrolls the display n pixels down.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) I00CN() error { //SCD nibble
+	c8.frameBuffer.ScrollDown(int(c8.cInstruction.n), c8.planes)
+	c8.MustDraw = true
+	return nil
+}
+
+//I00FB scrolls the display 4 pixels to the right.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) I00FB() error { //SCR
+	c8.frameBuffer.ScrollRight(4, c8.planes)
+	c8.MustDraw = true
+	return nil
+}
+
+//I00FC scrolls the display 4 pixels to the left.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) I00FC() error { //SCL
+	c8.frameBuffer.ScrollLeft(4, c8.planes)
+	c8.MustDraw = true
+	return nil
+}
+
+//I00FD exits the interpreter.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) I00FD() error { //EXIT
+	c8.Close()
+	return nil
+}
+
+//I00FE disables the high resolution mode, going back to the 64x32 display.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) I00FE() error { //LOW
+	c8.frameBuffer.SetHighRes(false)
+	c8.MustDraw = true
+	return nil
+}
+
+//I00FF enables the high resolution mode of 128x64 pixels.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) I00FF() error { //HIGH
+	c8.frameBuffer.SetHighRes(true)
+	c8.MustDraw = true
+	return nil
+}
+
+//IFX30 Set I = location of the 8x10 sprite for digit Vx.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) IFX30() error { //LD (HF, Vx)
+	vx := c8.registers[c8.cInstruction.x] & 0xF
+	c8.i = BigFontsetStartAddress + uint16(BigFontSize)*uint16(vx)
+	return nil
+}
+
+//IFX75 Stores registers V0 through Vx in the RPL user flags, which are persisted to disk.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) IFX75() error { //LD (R, Vx)
+	for k := 0; k <= int(c8.cInstruction.x); k++ {
+		c8.rplFlags[k] = c8.registers[k]
+	}
+	return c8.saveFlags()
+}
+
+//IFX85 Reads registers V0 through Vx from the RPL user flags.
+//This instruction is part of the SUPER-CHIP instruction set
+func (c8 *Chip8) IFX85() error { //LD (Vx, R)
+	for k := 0; k <= int(c8.cInstruction.x); k++ {
+		c8.registers[k] = c8.rplFlags[k]
+	}
+	return nil
+}
+
+//I00DN scrolls the display n pixels up.
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) I00DN() error { //SCU nibble
+	c8.frameBuffer.ScrollUp(int(c8.cInstruction.n), c8.planes)
+	c8.MustDraw = true
+	return nil
+}
+
+//I5XY2 Stores registers Vx through Vy in memory starting at location I, without modifying I.
+//If x > y, the registers are stored in reverse order.
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) I5XY2() error { //LD ([I], Vx - Vy)
+	x, y := int(c8.cInstruction.x), int(c8.cInstruction.y)
+	if err := c8.accessMemory(int(c8.i), abs(x-y)+1, true); err != nil {
+		return err
+	}
+	for k := 0; k <= abs(x-y); k++ {
+		c8.memory[int(c8.i)+k] = c8.registers[x+k*sign(y-x)]
+	}
+	return nil
+}
+
+//I5XY3 Reads registers Vx through Vy from memory starting at location I, without modifying I.
+//If x > y, the registers are read in reverse order.
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) I5XY3() error { //LD (Vx - Vy, [I])
+	x, y := int(c8.cInstruction.x), int(c8.cInstruction.y)
+	if err := c8.accessMemory(int(c8.i), abs(x-y)+1, false); err != nil {
+		return err
+	}
+	for k := 0; k <= abs(x-y); k++ {
+		c8.registers[x+k*sign(y-x)] = c8.memory[int(c8.i)+k]
+	}
+	return nil
+}
+
+//IF000 Set I = NNNN, where NNNN are the two bytes that follow the instruction, so it can address 64KB of memory.
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) IF000() error { //LD (I, long addr)
+	if err := checkMemory(int(c8.pc), 2); err != nil {
+		return err
+	}
+	c8.i = uint16(c8.memory[c8.pc])<<8 | uint16(c8.memory[c8.pc+1])
+	c8.pc += 2
+	return nil
+}
+
+//IFN01 Selects the planes in which CLS, DRW and the scroll instructions operate. n is a bitmask: 1, 2 or 3 for both planes
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) IFN01() error { //PLANE n
+	c8.planes = c8.cInstruction.x & monitor.AllPlanes
+	return nil
+}
+
+//IF002 Loads the 16 bytes starting at location I into the audio pattern buffer.
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) IF002() error { //AUDIO
+	if err := c8.accessMemory(int(c8.i), PatternSize, false); err != nil {
+		return err
+	}
+	copy(c8.audioPattern[:], c8.memory[c8.i:int(c8.i)+PatternSize])
+	return nil
+}
 
+//IFX3A Set the pitch of the audio pattern = Vx.
+//This instruction is part of the XO-CHIP instruction set
+func (c8 *Chip8) IFX3A() error { //PITCH Vx
+	c8.pitch = c8.registers[c8.cInstruction.x]
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
 }
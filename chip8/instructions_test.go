@@ -0,0 +1,63 @@
+package chip8
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChip8_I8XY4(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.registers[1] = 0xFF
+	c8.registers[2] = 0x02
+	execute(c8, 0x8124)
+	assert.Equal(t, byte(0x01), c8.registers[1], "")
+	assert.Equal(t, byte(1), c8.registers[0xF], "carry")
+
+	c8.registers[0xF] = 0xFF
+	execute(c8, 0x8F14)
+	assert.Equal(t, byte(1), c8.registers[0xF], "the carry is set after the result")
+}
+
+func TestChip8_I8XY5(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.registers[1] = 0x05
+	c8.registers[2] = 0x05
+	execute(c8, 0x8125)
+	assert.Equal(t, byte(0), c8.registers[1], "")
+	assert.Equal(t, byte(1), c8.registers[0xF], "there isn't a borrow when Vx equals Vy")
+
+	execute(c8, 0x8125)
+	assert.Equal(t, byte(0xFB), c8.registers[1], "")
+	assert.Equal(t, byte(0), c8.registers[0xF], "borrow")
+}
+
+func TestChip8_I8XY7(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.registers[1] = 0x05
+	c8.registers[2] = 0x05
+	execute(c8, 0x8127)
+	assert.Equal(t, byte(0), c8.registers[1], "")
+	assert.Equal(t, byte(1), c8.registers[0xF], "there isn't a borrow when Vy equals Vx")
+
+	c8.registers[1] = 0x06
+	execute(c8, 0x8127)
+	assert.Equal(t, byte(0xFF), c8.registers[1], "")
+	assert.Equal(t, byte(0), c8.registers[0xF], "borrow")
+}
+
+func TestChip8_ICXKK(t *testing.T) {
+	random := func(seed int64) []byte {
+		c8, _ := NewChip8(nil, Quirks{})
+		c8.SeedRandom(seed)
+		assert.Equal(t, seed, c8.RandomSeed(), "")
+		var values []byte
+		for k := 0; k < 16; k++ {
+			execute(c8, 0xC30F) //RND V3, 0F
+			assert.Equal(t, byte(0), c8.registers[3]&0xF0, "masked with kk")
+			values = append(values, c8.registers[3])
+		}
+		return values
+	}
+	assert.Equal(t, random(42), random(42), "the same seed gives the same numbers")
+	assert.NotEqual(t, random(42), random(43), "")
+}
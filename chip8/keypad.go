@@ -0,0 +1,59 @@
+package chip8
+
+import "sync/atomic"
+
+//Keypad is read by the chip8 to know which keys of its hex keypad are held down
+type Keypad interface {
+	IsPressed(key byte) bool
+}
+
+//KeypadState is a Keypad whose keys are pressed and released by the peripherals, such as the keyboard.
+//It can be written and read from different goroutines without locks, since the chip8 reads it on every cycle waiting for a key.
+//The zero value has every key up.
+type KeypadState struct {
+	keys uint32 //Bit k is set while the key k is held down, it's accessed atomically
+}
+
+//Press holds the key down until it's released
+func (k *KeypadState) Press(key byte) {
+	k.set(key, true)
+}
+
+//Release releases the key
+func (k *KeypadState) Release(key byte) {
+	k.set(key, false)
+}
+
+func (k *KeypadState) set(key byte, down bool) {
+	if key >= NumberOfKeys {
+		return
+	}
+	for {
+		old := atomic.LoadUint32(&k.keys)
+		keys := old &^ (1 << key)
+		if down {
+			keys |= 1 << key
+		}
+		if atomic.CompareAndSwapUint32(&k.keys, old, keys) {
+			return
+		}
+	}
+}
+
+//Keys returns the keys held down, the bit k is set if the key k is held down
+func (k *KeypadState) Keys() uint16 {
+	return uint16(atomic.LoadUint32(&k.keys))
+}
+
+//SetKeys holds down the keys whose bits are set, and releases the others
+func (k *KeypadState) SetKeys(keys uint16) {
+	atomic.StoreUint32(&k.keys, uint32(keys))
+}
+
+//IsPressed reports if the key is held down
+func (k *KeypadState) IsPressed(key byte) bool {
+	if key >= NumberOfKeys {
+		return false
+	}
+	return atomic.LoadUint32(&k.keys)&(1<<key) != 0
+}
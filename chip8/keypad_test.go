@@ -0,0 +1,70 @@
+package chip8
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChip8_SkipIfKeyHeld(t *testing.T) {
+	keypad := &KeypadState{}
+	c8, _ := NewChip8(keypad, Quirks{})
+	c8.registers[1] = 5
+
+	keypad.Press(5)
+	for k := 0; k < 2; k++ {
+		c8.pc = PCStartAddress
+		execute(c8, 0xE19E)
+		assert.Equal(t, uint16(PCStartAddress+2), c8.pc, "SKP V1 while the key is held, every time")
+		c8.pc = PCStartAddress
+		execute(c8, 0xE1A1)
+		assert.Equal(t, uint16(PCStartAddress), c8.pc, "SKNP V1 while the key is held")
+	}
+
+	keypad.Release(5)
+	c8.pc = PCStartAddress
+	execute(c8, 0xE19E)
+	assert.Equal(t, uint16(PCStartAddress), c8.pc, "SKP V1 after the key is released")
+	execute(c8, 0xE1A1)
+	assert.Equal(t, uint16(PCStartAddress+2), c8.pc, "SKNP V1 after the key is released")
+}
+
+func TestChip8_WaitKeyRelease(t *testing.T) {
+	keypad := &KeypadState{}
+	c8, _ := NewChip8(keypad, Quirks{})
+	copy(c8.memory[PCStartAddress:], []byte{0xF3, 0x0A}) //LD V3, K
+
+	assert.NoError(t, c8.Cycle(), "")
+	assert.Equal(t, uint16(PCStartAddress), c8.pc, "waiting for a key")
+
+	keypad.Press(0xC)
+	assert.NoError(t, c8.Cycle(), "")
+	assert.Equal(t, uint16(PCStartAddress), c8.pc, "waiting for the key to be released")
+	keypad.Press(0x2)
+	assert.NoError(t, c8.Cycle(), "")
+	assert.Equal(t, uint16(PCStartAddress), c8.pc, "")
+
+	keypad.Release(0xC)
+	assert.NoError(t, c8.Cycle(), "")
+	assert.Equal(t, uint16(PCStartAddress+2), c8.pc, "the key was released")
+	assert.Equal(t, byte(0xC), c8.registers[3], "the first key pressed")
+}
+
+func TestKeypadState(t *testing.T) {
+	keypad := &KeypadState{}
+	keypad.Press(0xF)
+	keypad.Press(0x10)
+	assert.True(t, keypad.IsPressed(0xF), "")
+	assert.False(t, keypad.IsPressed(0x10), "there are only 16 keys")
+	keypad.Release(0xF)
+	assert.False(t, keypad.IsPressed(0xF), "")
+}
+
+func TestKeypadState_SetKeys(t *testing.T) {
+	keypad := &KeypadState{}
+	keypad.Press(0x3)
+	keypad.SetKeys(1<<0xA | 1<<0x1)
+	assert.False(t, keypad.IsPressed(0x3), "")
+	assert.True(t, keypad.IsPressed(0xA), "")
+	assert.True(t, keypad.IsPressed(0x1), "")
+	assert.Equal(t, uint16(1<<0xA|1<<0x1), keypad.Keys(), "")
+}
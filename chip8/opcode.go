@@ -43,6 +43,27 @@ the numbers are identifiers
    Fx33 - LD B, Vx
    Fx55 - LD [I], Vx
    Fx65 - LD Vx, [I]
+
+SUPER-CHIP:
+   00Cn - SCD nibble
+   00FB - SCR
+   00FC - SCL
+   00FD - EXIT
+   00FE - LOW
+   00FF - HIGH
+   Dxy0 - DRW Vx, Vy, 0
+   Fx30 - LD HF, Vx
+   Fx75 - LD R, Vx
+   Fx85 - LD Vx, R
+
+XO-CHIP:
+   00Dn - SCU nibble
+   5xy2 - LD [I], Vx - Vy
+   5xy3 - LD Vx - Vy, [I]
+   F000 nnnn - LD I, long addr
+   Fn01 - PLANE n
+   F002 - AUDIO
+   Fx3A - PITCH Vx
 */
 type opcode uint16
 
@@ -51,8 +72,17 @@ func (oc opcode) TakeOpcodeID() uint16 {
 	first4Bits := (uint16(oc) & uint16(0xF000)) >> 12
 	switch first4Bits {
 	case uint16(0x0):
+		if uint16(oc)&uint16(0xFFF0) == uint16(0x00C0) {
+			return uint16(0x00C0)
+		}
+		if uint16(oc)&uint16(0xFFF0) == uint16(0x00D0) {
+			return uint16(0x00D0)
+		}
 		return uint16(oc)
 
+	case uint16(0x5):
+		return uint16(oc) & uint16(0xF00F)
+
 	case uint16(0x8):
 		return uint16(oc) & uint16(0xF00F)
 	case uint16(0x9):
@@ -62,7 +92,11 @@ func (oc opcode) TakeOpcodeID() uint16 {
 		return uint16(oc) & uint16(0xF0FF)
 
 	case uint16(0xF):
-		return uint16(oc) & uint16(0xF0FF)
+		//F000 and F002 don't have an X, the whole opcode is taken so FX00 and FX02 are unknown if X isn't 0
+		if id := uint16(oc) & uint16(0xF0FF); id != uint16(0xF000) && id != uint16(0xF002) {
+			return id
+		}
+		return uint16(oc)
 
 	default:
 		return uint16(oc) & uint16(0xF000)
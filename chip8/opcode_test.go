@@ -23,6 +23,28 @@ func TestOpcode_TakeOpcodeID(t *testing.T) {
 	assert.Equal(t, uint16(0x00E0), oc.TakeOpcodeID(), "")
 	oc = 0x00EE
 	assert.Equal(t, uint16(0x00EE), oc.TakeOpcodeID(), "")
+	oc = 0x00C5
+	assert.Equal(t, uint16(0x00C0), oc.TakeOpcodeID(), "")
+	oc = 0x00FF
+	assert.Equal(t, uint16(0x00FF), oc.TakeOpcodeID(), "")
+	oc = 0xF475
+	assert.Equal(t, uint16(0xF075), oc.TakeOpcodeID(), "")
+	oc = 0x00D2
+	assert.Equal(t, uint16(0x00D0), oc.TakeOpcodeID(), "")
+	oc = 0x5120
+	assert.Equal(t, uint16(0x5000), oc.TakeOpcodeID(), "")
+	oc = 0x5123
+	assert.Equal(t, uint16(0x5003), oc.TakeOpcodeID(), "")
+	oc = 0xF201
+	assert.Equal(t, uint16(0xF001), oc.TakeOpcodeID(), "")
+	oc = 0xF000
+	assert.Equal(t, uint16(0xF000), oc.TakeOpcodeID(), "")
+	oc = 0xF002
+	assert.Equal(t, uint16(0xF002), oc.TakeOpcodeID(), "")
+	oc = 0xF100
+	assert.Equal(t, uint16(0xF100), oc.TakeOpcodeID(), "FX00 isn't F000")
+	oc = 0xF302
+	assert.Equal(t, uint16(0xF302), oc.TakeOpcodeID(), "FX02 isn't F002")
 
 }
 
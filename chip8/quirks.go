@@ -0,0 +1,70 @@
+package chip8
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+//IndexIncrement indicates how much the instructions FX55 and FX65 move the index register after accessing memory
+type IndexIncrement byte
+
+const (
+	IncrementNone   IndexIncrement = iota //I is left untouched (SUPER-CHIP)
+	IncrementX                            //I = I + X (CHIP-48)
+	IncrementXPlus1                       //I = I + X + 1 (COSMAC VIP and XO-CHIP)
+)
+
+//Quirks selects how the chip8 interprets the opcodes whose behaviour changed between the platforms that ran CHIP-8 programs.
+//The zero value keeps the behaviour this emulator always had: shifts in place, I untouched by FX55/FX65, BNNN using V0,
+//no VF reset, clipped sprites and no display wait.
+type Quirks struct {
+	ShiftVY        bool           //8XY6 and 8XYE shift Vy and store the result in Vx, instead of shifting Vx in place
+	IndexIncrement IndexIncrement //How FX55 and FX65 modify I
+	JumpVX         bool           //BNNN jumps to XNN + VX, instead of NNN + V0
+	VFReset        bool           //8XY1, 8XY2 and 8XY3 set VF to 0
+	WrapSprites    bool           //DXYN wraps the pixels that fall outside the screen to the other side, instead of clipping them
+	DisplayWait    bool           //DXYN waits for the vertical blank, so at most one sprite is drawn every frame
+}
+
+//quirksProfiles are the named presets that can be selected in the configuration
+var quirksProfiles = map[string]Quirks{
+	"vip": {
+		ShiftVY:        true,
+		IndexIncrement: IncrementXPlus1,
+		VFReset:        true,
+		DisplayWait:    true,
+	},
+	"chip48": {
+		IndexIncrement: IncrementX,
+		JumpVX:         true,
+	},
+	"schip": {
+		IndexIncrement: IncrementNone,
+		JumpVX:         true,
+	},
+	"xochip": {
+		ShiftVY:        true,
+		IndexIncrement: IncrementXPlus1,
+		WrapSprites:    true,
+	},
+}
+
+//QuirksProfile returns the preset with the given name ("vip", "chip48", "schip" or "xochip")
+func QuirksProfile(name string) (Quirks, error) {
+	quirks, ok := quirksProfiles[strings.ToLower(name)]
+	if !ok {
+		return Quirks{}, errors.New("unknown quirks profile '" + name + "', expected one of: " + strings.Join(QuirksProfiles(), ", "))
+	}
+	return quirks, nil
+}
+
+//QuirksProfiles returns the names of all the presets, sorted alphabetically
+func QuirksProfiles() []string {
+	names := make([]string, 0, len(quirksProfiles))
+	for name := range quirksProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
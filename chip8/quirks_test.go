@@ -0,0 +1,118 @@
+package chip8
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+//execute runs a single opcode on the chip8, without fetching it from memory
+func execute(c8 *Chip8, oc uint16) {
+	c8.cOpcode = opcode(oc)
+	c8.executeOpcode()
+}
+
+func TestQuirksProfile(t *testing.T) {
+	vip, err := QuirksProfile("VIP")
+	assert.NoError(t, err, "error in QuirksProfile")
+	assert.True(t, vip.DisplayWait, "vip must wait for the vertical blank")
+
+	_, err = QuirksProfile("cosmac")
+	assert.Error(t, err, "unknown profile")
+	assert.Equal(t, []string{"chip48", "schip", "vip", "xochip"}, QuirksProfiles(), "")
+}
+
+func TestQuirks_ShiftVY(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.registers[1] = 0x81
+	c8.registers[2] = 0x02
+	execute(c8, 0x8126)
+	assert.Equal(t, byte(0x40), c8.registers[1], "SHR in place")
+	assert.Equal(t, byte(1), c8.registers[0xF], "VF")
+
+	c8, _ = NewChip8(nil, Quirks{ShiftVY: true})
+	c8.registers[1] = 0x81
+	c8.registers[2] = 0x82
+	execute(c8, 0x812E)
+	assert.Equal(t, byte(0x04), c8.registers[1], "SHL from Vy")
+	assert.Equal(t, byte(1), c8.registers[0xF], "VF")
+}
+
+func TestQuirks_IndexIncrement(t *testing.T) {
+	expected := map[IndexIncrement]uint16{
+		IncrementNone:   0x300,
+		IncrementX:      0x303,
+		IncrementXPlus1: 0x304,
+	}
+	for increment, i := range expected {
+		c8, _ := NewChip8(nil, Quirks{IndexIncrement: increment})
+		c8.i = 0x300
+		execute(c8, 0xF355)
+		assert.Equal(t, i, c8.i, "FX55")
+		c8.i = 0x300
+		execute(c8, 0xF365)
+		assert.Equal(t, i, c8.i, "FX65")
+	}
+}
+
+func TestQuirks_JumpVX(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.registers[0] = 0x10
+	c8.registers[2] = 0x20
+	execute(c8, 0xB240)
+	assert.Equal(t, uint16(0x250), c8.pc, "BNNN")
+
+	c8, _ = NewChip8(nil, Quirks{JumpVX: true})
+	c8.registers[0] = 0x10
+	c8.registers[2] = 0x20
+	execute(c8, 0xB240)
+	assert.Equal(t, uint16(0x260), c8.pc, "BXNN")
+}
+
+func TestQuirks_VFReset(t *testing.T) {
+	for _, oc := range []uint16{0x8011, 0x8012, 0x8013} {
+		c8, _ := NewChip8(nil, Quirks{})
+		c8.registers[0xF] = 1
+		execute(c8, oc)
+		assert.Equal(t, byte(1), c8.registers[0xF], "VF untouched")
+
+		c8, _ = NewChip8(nil, Quirks{VFReset: true})
+		c8.registers[0xF] = 1
+		execute(c8, oc)
+		assert.Equal(t, byte(0), c8.registers[0xF], "VF reset")
+	}
+}
+
+func TestQuirks_WrapSprites(t *testing.T) {
+	for _, wrap := range []bool{false, true} {
+		c8, _ := NewChip8(nil, Quirks{WrapSprites: wrap})
+		c8.memory[0x300] = 0xFF
+		c8.i = 0x300
+		c8.registers[0] = 60
+		c8.registers[1] = 31
+		execute(c8, 0xD012)
+
+		fb := c8.GetFrameBuffer()
+		assert.Equal(t, byte(1), *fb.Get(63, 31), "visible pixel")
+		assert.Equal(t, byte(0), *fb.Get(0, 0), "pixel of the second row")
+		if wrap {
+			assert.Equal(t, byte(1), *fb.Get(0, 31), "wrapped pixel")
+		} else {
+			assert.Equal(t, byte(0), *fb.Get(0, 31), "clipped pixel")
+		}
+	}
+}
+
+func TestQuirks_DisplayWait(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{DisplayWait: true})
+	//DRW V0, V0, 1 followed by LD V1, 1
+	copy(c8.memory[PCStartAddress:], []byte{0xD0, 0x01, 0x61, 0x01})
+
+	c8.Cycle()
+	c8.Cycle()
+	assert.Equal(t, uint16(PCStartAddress+2), c8.pc, "waiting for the vertical blank")
+	assert.Equal(t, byte(0), c8.registers[1], "")
+
+	c8.TickTimers()
+	c8.Cycle()
+	assert.Equal(t, byte(1), c8.registers[1], "resumed after the vertical blank")
+}
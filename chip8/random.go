@@ -0,0 +1,214 @@
+package chip8
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//RandomSource generates the random bytes of CXKK. Its state is kept by the chip8, so it's part of the save states,
+//and a program run twice with the same source, the same seed and the same inputs draws the same numbers.
+type RandomSource interface {
+	Start(seed int64) uint64                     //Start returns the state of the source for a seed
+	Next(state uint64, c8 *Chip8) (byte, uint64) //Next returns a random byte and the next state of the source
+}
+
+//Option configures a chip8 instantiated by NewChip8
+type Option func(c8 *Chip8)
+
+//WithRandom makes the chip8 draw the random numbers of CXKK from source, starting with seed
+func WithRandom(source RandomSource, seed int64) Option {
+	return func(c8 *Chip8) {
+		c8.randomSource = source
+		c8.SeedRandom(seed)
+	}
+}
+
+//PRNG is the default random source, a SplitMix64 generator whose state is the seed
+type PRNG struct{}
+
+func (PRNG) Start(seed int64) uint64 {
+	return uint64(seed)
+}
+
+func (PRNG) Next(state uint64, _ *Chip8) (byte, uint64) {
+	state += 0x9E3779B97F4A7C15
+	z := state
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return byte(z >> 56), state
+}
+
+//VIPInterpreterSize is the size of the CHIP-8 interpreter of the COSMAC VIP, which was loaded at the address 0x0000
+const VIPInterpreterSize = 0x200
+
+//VIPRandom draws the random numbers with the routine of CXKK of the interpreter of the COSMAC VIP. Its state is the register R9 of the 1802:
+//CXKK increments the whole register, its low byte points to a byte of the page 0x0100 of the interpreter, and that byte is added to its high byte,
+//which is the random number. The VIPRandom is that page, 256 bytes, which are read from a dump of the interpreter by ParseVIPInterpreter
+//because the interpreter isn't distributed with the emulator. The seed is the starting value of R9.
+type VIPRandom []byte
+
+func (VIPRandom) Start(seed int64) uint64 {
+	return uint64(uint16(seed))
+}
+
+func (p VIPRandom) Next(state uint64, _ *Chip8) (byte, uint64) {
+	r9 := uint16(state) + 1
+	high := byte(r9>>8) + p[byte(r9)]
+	return high, uint64(high)<<8 | uint64(byte(r9))
+}
+
+//ParseVIPInterpreter reads a dump of the CHIP-8 interpreter of the COSMAC VIP, its VIPInterpreterSize bytes,
+//and returns the random source which reads its page 0x0100
+func ParseVIPInterpreter(r io.Reader) (VIPRandom, error) {
+	interpreter, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(interpreter) != VIPInterpreterSize {
+		return nil, fmt.Errorf("the interpreter of the COSMAC VIP has %d bytes, not %d", len(interpreter), VIPInterpreterSize)
+	}
+	return VIPRandom(interpreter[0x100:]), nil
+}
+
+//Sequence draws the random numbers from a fixed sequence of bytes, which starts again after its last byte.
+//The seed is the position of the first number in the sequence.
+type Sequence []byte
+
+func (s Sequence) Start(seed int64) uint64 {
+	if seed < 0 {
+		return 0
+	}
+	return uint64(seed)
+}
+
+func (s Sequence) Next(state uint64, _ *Chip8) (byte, uint64) {
+	return s[state%uint64(len(s))], state + 1
+}
+
+//ParseSequence reads a sequence of random numbers written as hexadecimal bytes separated by spaces or lines, such as "A3 07 FF".
+//The text after a ; in a line is a comment.
+func ParseSequence(r io.Reader) (Sequence, error) {
+	text, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var s Sequence
+	for n, line := range strings.Split(string(text), "\n") {
+		if k := strings.Index(line, ";"); k >= 0 {
+			line = line[:k]
+		}
+		for _, field := range strings.Fields(line) {
+			b, err := strconv.ParseUint(field, 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: '%s' is not a hexadecimal byte", n+1, field)
+			}
+			s = append(s, byte(b))
+		}
+	}
+	if len(s) == 0 {
+		return nil, errors.New("the sequence of random numbers is empty")
+	}
+	return s, nil
+}
+
+//ParseRandom returns the option of the random source with the given name: "prng", "sequence", which reads its bytes from the file filename,
+//or "vip", which reads the interpreter of the COSMAC VIP from the file filename.
+//The seed is a decimal number, or "clock" (or empty) to take a different seed from the clock on every run.
+func ParseRandom(name string, seed string, filename string) (Option, error) {
+	source, err := randomSource(name, filename)
+	if err != nil {
+		return nil, err
+	}
+	if seed == "" || strings.EqualFold(seed, "clock") {
+		return WithRandom(source, time.Now().UnixNano()), nil
+	}
+	n, err := strconv.ParseInt(seed, 10, 64)
+	if err != nil {
+		return nil, errors.New("'" + seed + "' is not a seed, expected a number or clock")
+	}
+	return WithRandom(source, n), nil
+}
+
+//RandomOption returns the option of the random source with the given name and seed, as the movies and the golden traces record them.
+//The bytes of the "sequence" source and the page of the interpreter of the "vip" source are given by data instead of a file, as RandomData returns them,
+//the other sources ignore it.
+func RandomOption(name string, seed int64, data []byte) (Option, error) {
+	switch strings.ToLower(name) {
+	case "sequence":
+		if len(data) == 0 {
+			return nil, errors.New("the sequence of random numbers is empty")
+		}
+		return WithRandom(Sequence(data), seed), nil
+	case "vip":
+		if len(data) != VIPInterpreterSize/2 {
+			return nil, fmt.Errorf("the vip random source needs the %d bytes of the page 0x0100 of the interpreter", VIPInterpreterSize/2)
+		}
+		return WithRandom(VIPRandom(data), seed), nil
+	}
+	source, err := randomSource(name, "")
+	if err != nil {
+		return nil, err
+	}
+	return WithRandom(source, seed), nil
+}
+
+//RandomData returns the bytes which the random source draws its numbers from, which a recording of a run must keep since they come from a file:
+//the bytes of a Sequence or the page of the interpreter of a VIPRandom. The other sources don't have any.
+func RandomData(source RandomSource) []byte {
+	switch s := source.(type) {
+	case Sequence:
+		return s
+	case VIPRandom:
+		return s
+	}
+	return nil
+}
+
+//randomSource returns the random source with the given name, the sequence and the vip sources read the file filename
+func randomSource(name string, filename string) (RandomSource, error) {
+	switch strings.ToLower(name) {
+	case "", "prng":
+		return PRNG{}, nil
+	case "sequence", "vip":
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if strings.EqualFold(name, "vip") {
+			return ParseVIPInterpreter(f)
+		}
+		return ParseSequence(f)
+	}
+	return nil, errors.New("unknown random source '" + name + "', expected prng, sequence or vip")
+}
+
+//SeedRandom restarts the random source with seed, so two runs of a program with the same seed and the same inputs are the same
+func (c8 *Chip8) SeedRandom(seed int64) {
+	c8.seed = seed
+	c8.randomState = c8.randomSource.Start(seed)
+}
+
+//RandomSource returns the source of the random numbers of CXKK
+func (c8 *Chip8) RandomSource() RandomSource {
+	return c8.randomSource
+}
+
+//RandomSeed returns the seed with which the random source started
+func (c8 *Chip8) RandomSeed() int64 {
+	return c8.seed
+}
+
+//randomByte draws the next random byte
+func (c8 *Chip8) randomByte() byte {
+	var b byte
+	b, c8.randomState = c8.randomSource.Next(c8.randomState, c8)
+	return b
+}
@@ -0,0 +1,144 @@
+package chip8
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//draw executes RND V0, FF n times and returns the numbers drawn
+func draw(c8 *Chip8, n int) []byte {
+	var values []byte
+	for k := 0; k < n; k++ {
+		execute(c8, 0xC0FF)
+		values = append(values, c8.registers[0])
+	}
+	return values
+}
+
+func TestNewChip8_Random(t *testing.T) {
+	first, _ := NewChip8(nil, Quirks{})
+	second, _ := NewChip8(nil, Quirks{})
+	assert.Equal(t, int64(0), first.RandomSeed(), "the default seed is 0")
+	assert.Equal(t, draw(first, 32), draw(second, 32), "the default random numbers are always the same")
+
+	seeded, _ := NewChip8(nil, Quirks{}, WithRandom(PRNG{}, 7))
+	assert.Equal(t, int64(7), seeded.RandomSeed(), "")
+	assert.Equal(t, int64(7), seeded.Dump().Seed, "the seed is in the dump of the state")
+	assert.NotEqual(t, draw(first, 32), draw(seeded, 32), "")
+}
+
+//vipInterpreter is a dump of an interpreter whose page 0x0100 has the bytes 0x00, 0x03, 0x06...
+func vipInterpreter() []byte {
+	interpreter := make([]byte, VIPInterpreterSize)
+	for i := 0; i < 0x100; i++ {
+		interpreter[0x100+i] = byte(3 * i)
+	}
+	return interpreter
+}
+
+func TestVIPRandom(t *testing.T) {
+	page, err := ParseVIPInterpreter(bytes.NewReader(vipInterpreter()))
+	assert.NoError(t, err, "")
+	assert.Equal(t, 0x100, len(page), "")
+	assert.Equal(t, byte(3), page[1], "")
+
+	c8, _ := NewChip8(nil, Quirks{}, WithRandom(page, 0x10FE))
+	//R9 goes to 10FF and its high byte to 10+FD, to 0E00 with the carry and 0E+00, and to 0E01 and 0E+03
+	assert.Equal(t, []byte{0x0D, 0x0E, 0x11}, draw(c8, 3), "")
+	r9 := uint16(0x1101)
+	for _, got := range draw(c8, 600) {
+		r9++
+		r9 = uint16(byte(r9>>8)+page[byte(r9)])<<8 | r9&0xFF
+		assert.Equal(t, byte(r9>>8), got, "")
+	}
+
+	_, err = ParseVIPInterpreter(bytes.NewReader(vipInterpreter()[:0x1FF]))
+	assert.Error(t, err, "")
+	_, err = ParseVIPInterpreter(bytes.NewReader(append(vipInterpreter(), 0)))
+	assert.Error(t, err, "")
+}
+
+func TestSequence(t *testing.T) {
+	sequence, err := ParseSequence(strings.NewReader("; dice\n01 02 03\n04 05 06 ; the last one\n"))
+	assert.NoError(t, err, "")
+	assert.Equal(t, Sequence{1, 2, 3, 4, 5, 6}, sequence, "")
+
+	c8, _ := NewChip8(nil, Quirks{}, WithRandom(sequence, 4))
+	assert.Equal(t, []byte{5, 6, 1, 2, 3, 4, 5, 6, 1}, draw(c8, 9), "it starts at the seed and starts again after the end")
+	execute(c8, 0xC10E) //RND V1, 0E
+	assert.Equal(t, byte(2), c8.registers[1], "masked with kk")
+
+	_, err = ParseSequence(strings.NewReader("01 1G"))
+	assert.EqualError(t, err, "line 1: '1G' is not a hexadecimal byte", "")
+	_, err = ParseSequence(strings.NewReader("; nothing\n"))
+	assert.Error(t, err, "")
+}
+
+func TestParseRandom(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "vip.bin")
+	assert.NoError(t, ioutil.WriteFile(filename, vipInterpreter(), 0644), "")
+	option, err := ParseRandom("VIP", "42", filename)
+	assert.NoError(t, err, "")
+	c8, _ := NewChip8(nil, Quirks{}, option)
+	assert.Equal(t, VIPRandom(vipInterpreter()[0x100:]), c8.randomSource, "")
+	assert.Equal(t, int64(42), c8.RandomSeed(), "")
+
+	_, err = ParseRandom("prng", "clock", "")
+	assert.NoError(t, err, "")
+	_, err = ParseRandom("prng", "soon", "")
+	assert.Error(t, err, "")
+	_, err = ParseRandom("vip", "1", "missing.bin")
+	assert.Error(t, err, "")
+	_, err = ParseRandom("dice", "1", "")
+	assert.Error(t, err, "")
+	_, err = ParseRandom("sequence", "1", "missing.txt")
+	assert.Error(t, err, "")
+}
+
+func TestChip8_SaveState_Random(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{}, WithRandom(PRNG{}, 99))
+	draw(c8, 10)
+	var saved bytes.Buffer
+	assert.NoError(t, c8.SaveState(&saved), "")
+	expected := draw(c8, 10)
+
+	other, _ := NewChip8(nil, Quirks{})
+	assert.NoError(t, other.LoadState(&saved), "")
+	assert.Equal(t, int64(99), other.RandomSeed(), "")
+	assert.Equal(t, expected, draw(other, 10), "the random numbers continue from the state")
+}
+
+func TestRandomOption(t *testing.T) {
+	option, err := RandomOption("sequence", 1, []byte{7, 8, 9})
+	assert.NoError(t, err, "")
+	c8, _ := NewChip8(nil, Quirks{}, option)
+	assert.Equal(t, Sequence{7, 8, 9}, c8.RandomSource(), "")
+	assert.Equal(t, []byte{8, 9, 7}, draw(c8, 3), "")
+
+	assert.Equal(t, []byte{7, 8, 9}, RandomData(c8.RandomSource()), "")
+
+	page := vipInterpreter()[0x100:]
+	option, err = RandomOption("vip", 1, page)
+	assert.NoError(t, err, "")
+	c8, _ = NewChip8(nil, Quirks{}, option)
+	assert.Equal(t, VIPRandom(page), c8.RandomSource(), "")
+	assert.Equal(t, page, RandomData(c8.RandomSource()), "")
+
+	option, err = RandomOption("prng", 42, []byte{7})
+	assert.NoError(t, err, "")
+	c8, _ = NewChip8(nil, Quirks{}, option)
+	assert.Equal(t, PRNG{}, c8.RandomSource(), "the other sources ignore the data")
+	assert.Equal(t, int64(42), c8.RandomSeed(), "")
+	assert.Nil(t, RandomData(c8.RandomSource()), "")
+
+	_, err = RandomOption("sequence", 0, nil)
+	assert.Error(t, err, "")
+	_, err = RandomOption("vip", 0, page[:0x80])
+	assert.Error(t, err, "")
+	_, err = RandomOption("dice", 0, nil)
+	assert.Error(t, err, "")
+}
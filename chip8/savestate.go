@@ -0,0 +1,179 @@
+package chip8
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	StateMagic   = "C8ST" //The files of the save states start with these bytes
+	StateVersion = 2      //Version of the format of the save states, it changes every time Snapshot changes
+)
+
+var (
+	ErrInvalidState  = errors.New("not a chip8 save state")
+	ErrStateVersion  = errors.New("unsupported version of save state")
+	ErrStateChecksum = errors.New("the save state is corrupted")
+)
+
+//Snapshot is the whole state of a chip8 at a point of its execution. It has a fixed size, so it can be encoded with encoding/binary.
+type Snapshot struct {
+	Memory [AddressSpace]byte
+	Machine
+	Random
+}
+
+//Machine is the state of a chip8 but its memory: the registers, the timers, the keypad and the display
+type Machine struct {
+	Registers  [NumberOfRegisters]byte
+	PC         uint16
+	I          uint16
+	Stack      [StackLevels]uint16
+	SP         byte
+	DelayTimer byte
+	SoundTimer byte
+
+	Keys       uint16 //Bit k is set if the key k is held down
+	WaitingKey bool
+	WaitedKey  int8
+
+	FrameBuffer   monitor.FrameBuffer
+	WaitingVBlank bool
+
+	RPLFlags     [NumberOfFlags]byte
+	Planes       byte
+	AudioPattern [PatternSize]byte
+	Pitch        byte
+	Cycles       uint64
+}
+
+//Random is the state of the random source of CXKK, kept apart from the Machine so the hashes of the golden traces don't depend on it
+type Random struct {
+	Seed        int64  //Seed with which the random source started
+	RandomState uint64 //State of the random source, so the random numbers continue like they would have from the state
+}
+
+//Snapshot takes the state of the chip8. If its keypad is a *KeypadState, the keys held down are part of the state.
+func (c8 *Chip8) Snapshot() *Snapshot {
+	s := &Snapshot{Machine: Machine{
+		Registers:     c8.registers,
+		PC:            c8.pc,
+		I:             c8.i,
+		Stack:         c8.stack,
+		SP:            c8.sp,
+		DelayTimer:    c8.delayTimer,
+		SoundTimer:    c8.soundTimer,
+		WaitingKey:    c8.waitingKey,
+		WaitedKey:     int8(c8.waitedKey),
+		FrameBuffer:   c8.frameBuffer,
+		WaitingVBlank: c8.waitingVBlank,
+		RPLFlags:      c8.rplFlags,
+		Planes:        c8.planes,
+		AudioPattern:  c8.audioPattern,
+		Pitch:         c8.pitch,
+		Cycles:        c8.cycles,
+	}, Random: Random{
+		Seed:        c8.seed,
+		RandomState: c8.randomState,
+	}}
+	copy(s.Memory[:], c8.memory[:AddressSpace])
+	if keypad, ok := c8.keypad.(*KeypadState); ok {
+		for key := byte(0); key < NumberOfKeys; key++ {
+			if keypad.IsPressed(key) {
+				s.Keys |= 1 << key
+			}
+		}
+	}
+	return s
+}
+
+//Restore sets the state of the chip8 to a snapshot. If its keypad is a *KeypadState, the keys held down are restored too.
+func (c8 *Chip8) Restore(s *Snapshot) {
+	copy(c8.memory[:AddressSpace], s.Memory[:])
+	c8.registers = s.Registers
+	c8.pc = s.PC
+	c8.i = s.I
+	c8.stack = s.Stack
+	c8.sp = s.SP
+	c8.delayTimer = s.DelayTimer
+	c8.soundTimer = s.SoundTimer
+	c8.waitingKey = s.WaitingKey
+	c8.waitedKey = int(s.WaitedKey)
+	c8.frameBuffer = s.FrameBuffer
+	c8.waitingVBlank = s.WaitingVBlank
+	c8.rplFlags = s.RPLFlags
+	c8.planes = s.Planes
+	c8.audioPattern = s.AudioPattern
+	c8.pitch = s.Pitch
+	c8.cycles = s.Cycles
+	c8.seed = s.Seed
+	c8.randomState = s.RandomState
+	c8.MustDraw = true
+	if keypad, ok := c8.keypad.(*KeypadState); ok {
+		for key := byte(0); key < NumberOfKeys; key++ {
+			if s.Keys&(1<<key) != 0 {
+				keypad.Press(key)
+			} else {
+				keypad.Release(key)
+			}
+		}
+	}
+}
+
+//SaveState writes the state of the chip8 to w with the format:
+//	"C8ST" | version (uint16) | CRC-32 of the snapshot (uint32) | snapshot compressed with DEFLATE
+//The numbers are big endian, and the snapshot is the binary encoding of Snapshot.
+func (c8 *Chip8) SaveState(w io.Writer) error {
+	var payload bytes.Buffer
+	if err := binary.Write(&payload, binary.BigEndian, c8.Snapshot()); err != nil {
+		return err
+	}
+
+	header := make([]byte, len(StateMagic)+2+4)
+	copy(header, StateMagic)
+	binary.BigEndian.PutUint16(header[len(StateMagic):], StateVersion)
+	binary.BigEndian.PutUint32(header[len(StateMagic)+2:], crc32.ChecksumIEEE(payload.Bytes()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	compressor, err := flate.NewWriter(w, flate.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err = compressor.Write(payload.Bytes()); err != nil {
+		return err
+	}
+	return compressor.Close()
+}
+
+//LoadState reads a state written by SaveState and sets the chip8 to it.
+//If the state is invalid, it returns ErrInvalidState, ErrStateVersion or ErrStateChecksum and the chip8 doesn't change.
+func (c8 *Chip8) LoadState(r io.Reader) error {
+	header := make([]byte, len(StateMagic)+2+4)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:len(StateMagic)]) != StateMagic {
+		return ErrInvalidState
+	}
+	if binary.BigEndian.Uint16(header[len(StateMagic):]) != StateVersion {
+		return ErrStateVersion
+	}
+
+	size := binary.Size(Snapshot{})
+	payload, err := ioutil.ReadAll(io.LimitReader(flate.NewReader(r), int64(size)+1))
+	if err != nil || len(payload) != size || crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[len(StateMagic)+2:]) {
+		return ErrStateChecksum
+	}
+
+	s := new(Snapshot)
+	if err = binary.Read(bytes.NewReader(payload), binary.BigEndian, s); err != nil {
+		return err
+	}
+	c8.Restore(s)
+	return nil
+}
@@ -0,0 +1,84 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func runPong(t *testing.T, keypad Keypad) *Chip8 {
+	cfg := ObtainConfig()
+	c8, err := NewChip8(keypad, Quirks{})
+	assert.NoError(t, err, "error in NewChip8")
+	absPathROM, _ := filepath.Abs(cfg.Test.ROM1)
+	assert.NoError(t, c8.LoadROM(absPathROM), "error in LoadROM")
+	absPathFonts, _ := filepath.Abs(cfg.Test.FONT)
+	assert.NoError(t, c8.LoadFonts(absPathFonts), "error in LoadFonts")
+	return c8
+}
+
+func TestChip8_SaveState(t *testing.T) {
+	keypad := &KeypadState{}
+	c8 := runPong(t, keypad)
+	for k := 0; k < 500; k++ {
+		assert.NoError(t, c8.Cycle(), "")
+		if k%8 == 0 {
+			c8.TickTimers()
+		}
+	}
+	keypad.Press(4)
+
+	var saved bytes.Buffer
+	assert.NoError(t, c8.SaveState(&saved), "error in SaveState")
+	assert.True(t, saved.Len() < 4096, "the state must be compact, it has %d bytes", saved.Len())
+	expected := c8.Snapshot()
+
+	for k := 0; k < 500; k++ {
+		assert.NoError(t, c8.Cycle(), "")
+	}
+	keypad.Release(4)
+
+	assert.NoError(t, c8.LoadState(bytes.NewReader(saved.Bytes())), "error in LoadState")
+	assert.Equal(t, expected, c8.Snapshot(), "state after LoadState")
+	assert.True(t, keypad.IsPressed(4), "the keys are restored")
+
+	restored := runPong(t, nil)
+	assert.NoError(t, restored.LoadState(bytes.NewReader(saved.Bytes())), "load into another chip8")
+	for k := 0; k < 100; k++ {
+		assert.NoError(t, c8.Cycle(), "")
+		assert.NoError(t, restored.Cycle(), "")
+	}
+	assert.Equal(t, c8.Registers(), restored.Registers(), "both continue in the same way")
+}
+
+func TestChip8_LoadStateErrors(t *testing.T) {
+	c8 := runPong(t, nil)
+	var saved bytes.Buffer
+	assert.NoError(t, c8.SaveState(&saved), "")
+	state := saved.Bytes()
+
+	corrupt := func(k int) []byte {
+		data := append([]byte{}, state...)
+		data[k] ^= 0xFF
+		return data
+	}
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte("C8"), ErrInvalidState},
+		{corrupt(0), ErrInvalidState},
+		{corrupt(5), ErrStateVersion},
+		{corrupt(7), ErrStateChecksum},
+		{state[:len(state)-4], ErrStateChecksum},
+	}
+	for _, test := range tests {
+		other := runPong(t, nil)
+		other.registers[0] = 0x42
+		err := other.LoadState(bytes.NewReader(test.data))
+		assert.True(t, errors.Is(err, test.err), "expected %v, got %v", test.err, err)
+		assert.Equal(t, byte(0x42), other.registers[0], "the chip8 doesn't change")
+	}
+}
@@ -0,0 +1,77 @@
+package chip8
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestChip8_HighResSprite(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	execute(c8, 0x00FF)
+	fb := c8.GetFrameBuffer()
+	assert.Equal(t, 128, fb.Width(), "HIGH")
+	assert.Equal(t, 64, fb.Height(), "HIGH")
+
+	for k := 0; k < 32; k++ {
+		c8.memory[0x300+k] = 0xFF
+	}
+	c8.i = 0x300
+	c8.registers[0] = 120
+	c8.registers[1] = 60
+	execute(c8, 0xD010)
+
+	fb = c8.GetFrameBuffer()
+	assert.Equal(t, byte(1), *fb.Get(127, 63), "16x16 sprite")
+	assert.Equal(t, byte(1), *fb.Get(120, 60), "16x16 sprite")
+	assert.Equal(t, byte(0), *fb.Get(119, 60), "")
+	assert.Equal(t, byte(0), c8.registers[0xF], "no collision")
+
+	execute(c8, 0x00FE)
+	fb = c8.GetFrameBuffer()
+	assert.Equal(t, 64, fb.Width(), "LOW")
+	assert.Equal(t, byte(0), *fb.Get(0, 0), "LOW clears the display")
+}
+
+func TestChip8_Scroll(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	*c8.frameBuffer.Get(10, 10) = 1
+
+	execute(c8, 0x00C3)
+	fb := c8.GetFrameBuffer()
+	assert.Equal(t, byte(1), *fb.Get(10, 13), "SCD 3")
+
+	execute(c8, 0x00FB)
+	fb = c8.GetFrameBuffer()
+	assert.Equal(t, byte(1), *fb.Get(14, 13), "SCR")
+
+	execute(c8, 0x00FC)
+	execute(c8, 0x00FC)
+	fb = c8.GetFrameBuffer()
+	assert.Equal(t, byte(1), *fb.Get(6, 13), "SCL")
+	assert.Equal(t, byte(0), *fb.Get(14, 13), "")
+}
+
+func TestChip8_BigFont(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.registers[3] = 9
+	execute(c8, 0xF330)
+	assert.Equal(t, uint16(BigFontsetStartAddress+9*BigFontSize), c8.i, "LD HF, V3")
+	assert.Equal(t, bigFontset[9*BigFontSize:10*BigFontSize], c8.memory[c8.i:c8.i+BigFontSize], "")
+}
+
+func TestChip8_RPLFlags(t *testing.T) {
+	flags := filepath.Join(t.TempDir(), "chip8.flags")
+
+	c8, _ := NewChip8(nil, Quirks{})
+	assert.NoError(t, c8.LoadFlags(flags), "missing flags file")
+	c8.registers[0] = 7
+	c8.registers[1] = 42
+	execute(c8, 0xF175)
+
+	c8, _ = NewChip8(nil, Quirks{})
+	assert.NoError(t, c8.LoadFlags(flags), "error in LoadFlags")
+	execute(c8, 0xF185)
+	assert.Equal(t, byte(7), c8.registers[0], "flags persisted")
+	assert.Equal(t, byte(42), c8.registers[1], "flags persisted")
+}
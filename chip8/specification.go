@@ -8,16 +8,19 @@ const (
 	//0x050 to 0x0A0 to store Fonts.
 	//TotalMemory    = 4096
 	TotalMemory    = 400096
-	MemoryForROM   = 0xFFF - 0x200 //Amount of memory reserved for ROMs Files
-	MemoryForFonts = 0x0A0 - 0x050 //Amount of memory reserved for Fonts
+	AddressSpace   = 0x10000              //Programs can address 64KB of memory, every access out of it is an error
+	MemoryForROM   = AddressSpace - 0x200 //Amount of memory reserved for ROMs Files, XO-CHIP programs can use 64KB of memory
+	MemoryForFonts = 0x0A0 - 0x050        //Amount of memory reserved for Fonts
 	//There are 16 fonts (0 to F), each is represented by 5 bytes
-	NumberOfRegisters  = 16
-	StackLevels        = 16
-	NumberOfKeys       = 16
-	FontSize           = 5                                   //every font is represented by 5 bytes
-	Frequency          = time.Second / time.Duration(500)    //The frequency should be 60Hz but that is very slow
-	FrequencyDebugMode = time.Second / time.Duration(500000) //In debug mode the frequency must be smaller, because it's a slower mode
-	WidthScreen        = 64
-	HeightScreen       = 32
-	AsciiEscape        = 0x1B
+	BigFontsetStartAddress = 0x0A0 //The 8x10 font of the SUPER-CHIP is stored from 0x0A0 to 0x140
+	NumberOfRegisters      = 16
+	StackLevels            = 16
+	NumberOfKeys           = 16
+	FontSize               = 5                               //every font is represented by 5 bytes
+	BigFontSize            = 10                              //every font of the SUPER-CHIP is represented by 10 bytes
+	NumberOfFlags          = 16                              //FX75 and FX85 save and load up to 16 registers into the RPL user flags
+	PatternSize            = 16                              //The XO-CHIP audio pattern buffer has 16 bytes
+	DefaultPitch           = 64                              //With this pitch the audio pattern is played at 4000 bits per second
+	DefaultIPF             = 8                               //Instructions executed per frame by default, about 500 instructions per second
+	RefreshRate            = time.Second / time.Duration(60) //The screen is refreshed 60 times per second, which is when a vertical blank happens and the timers are counted back
 )
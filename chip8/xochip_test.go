@@ -0,0 +1,68 @@
+package chip8
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChip8_LongLoad(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	//SE V0, 0 skips the whole F000 1234, then LD V1, 1
+	copy(c8.memory[PCStartAddress:], []byte{0x30, 0x00, 0xF0, 0x00, 0x12, 0x34, 0x61, 0x01, 0xF0, 0x00, 0xAB, 0xCD})
+
+	c8.Cycle()
+	assert.Equal(t, uint16(PCStartAddress+6), c8.pc, "skip over a 4 bytes instruction")
+	c8.Cycle()
+	c8.Cycle()
+	assert.Equal(t, uint16(0xABCD), c8.i, "LD I, long addr")
+	assert.Equal(t, uint16(PCStartAddress+12), c8.pc, "")
+}
+
+func TestChip8_Planes(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.memory[0x300] = 0x80
+	c8.memory[0x301] = 0x80
+	c8.i = 0x300
+
+	execute(c8, 0xF301)
+	execute(c8, 0xD001)
+	fb := c8.GetFrameBuffer()
+	assert.Equal(t, byte(3), *fb.Get(0, 0), "drawn in both planes")
+
+	execute(c8, 0xF201)
+	execute(c8, 0x00E0)
+	fb = c8.GetFrameBuffer()
+	assert.Equal(t, byte(1), *fb.Get(0, 0), "CLS only clears the second plane")
+
+	execute(c8, 0xF101)
+	execute(c8, 0xD001)
+	assert.Equal(t, byte(1), c8.registers[0xF], "collision in the first plane")
+}
+
+func TestChip8_SaveLoadRange(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	c8.i = 0x300
+	c8.registers[2], c8.registers[3], c8.registers[4] = 1, 2, 3
+
+	execute(c8, 0x5242)
+	assert.Equal(t, []byte{1, 2, 3}, c8.memory[0x300:0x303], "LD [I], V2 - V4")
+	assert.Equal(t, uint16(0x300), c8.i, "I is not modified")
+
+	execute(c8, 0x5A83)
+	assert.Equal(t, []byte{1, 2, 3}, []byte{c8.registers[0xA], c8.registers[9], c8.registers[8]}, "LD VA - V8, [I]")
+}
+
+func TestChip8_AudioPattern(t *testing.T) {
+	c8, _ := NewChip8(nil, Quirks{})
+	for k := 0; k < PatternSize; k++ {
+		c8.memory[0x300+k] = byte(k)
+	}
+	c8.i = 0x300
+	c8.registers[5] = 112
+	execute(c8, 0xF002)
+	execute(c8, 0xF53A)
+
+	pattern, pitch := c8.AudioPattern()
+	assert.Equal(t, c8.memory[0x300:0x300+PatternSize], pattern[:], "AUDIO")
+	assert.Equal(t, byte(112), pitch, "PITCH V5")
+}
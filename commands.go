@@ -0,0 +1,705 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/app"
+	"github.com/NoetherianRing/Chip-8/asm"
+	"github.com/NoetherianRing/Chip-8/audio"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/config"
+	"github.com/NoetherianRing/Chip-8/disasm"
+	"github.com/NoetherianRing/Chip-8/golden"
+	"github.com/NoetherianRing/Chip-8/headless"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"github.com/NoetherianRing/Chip-8/movie"
+	"github.com/NoetherianRing/Chip-8/recorder"
+	"github.com/NoetherianRing/Chip-8/romdb"
+	"github.com/NoetherianRing/Chip-8/terminal"
+	"github.com/NoetherianRing/Chip-8/trace"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//commands are the commands which can be given as the first argument of the app
+var commands = map[string]func(args []string) error{
+	"asm":    assemble,
+	"disasm": disassemble,
+	"info":   info,
+	"record": recordGolden,
+	"run":    runROM,
+	"test":   testSuite,
+	"verify": verifyGolden,
+}
+
+const usage = `usage: chip8 <command> [flags] [arguments]
+
+The commands are:
+  run     run a ROM in a window, or without it with --headless
+  test    run Timendus' test suite without a window and show the screen of every test
+  record  record a golden trace of a run of a ROM without a window
+  verify  run a ROM again like a golden trace and report where it diverges
+  info    show what is known about a ROM
+  disasm  disassemble a ROM
+  asm     assemble a source file into a ROM
+
+Without a command, the ROM of the configuration is run in a window.
+Use chip8 <command> -h to see the flags of a command.
+`
+
+//profiles are the quirks profiles suggested for the programs of every platform
+var profiles = map[string]string{
+	disasm.PlatformCHIP8:  "vip",
+	disasm.PlatformSCHIP:  "schip",
+	disasm.PlatformXOCHIP: "xochip",
+}
+
+//info writes what can be known about the ROM given in args without running it: its size, its SHA-1 hash, the platform it needs,
+//and the quirks profile suggested for it. If the ROM is in the ROM database, it also writes its entry, whose profile replaces the suggested one.
+func info(args []string) error {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	files := addConfigFiles(flags)
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8 info [--config config.yml] [--romdb roms.yml] rom.ch8")
+	}
+	cfg, err := files.load()
+	if err != nil {
+		return err
+	}
+	db, err := romdb.Open(cfg.Paths.ROMDB)
+	if err != nil {
+		return err
+	}
+	rom, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	program := disasm.Disassemble(rom)
+	platform := program.Platform()
+	entry, found := db.Lookup(rom)
+	profile := profiles[platform]
+	if entry.Profile() != "" {
+		profile = entry.Profile()
+	}
+	fmt.Printf("File      %s\n", flags.Arg(0))
+	fmt.Printf("Size      %d bytes\n", len(rom))
+	fmt.Printf("SHA-1     %s\n", romdb.Hash(rom))
+	fmt.Printf("Platform  %s\n", platform)
+	fmt.Printf("Quirks    %s\n", profile)
+	fmt.Printf("Code      %d instructions, %d labels\n", len(program.Instructions()), len(program.Labels()))
+	if !found {
+		fmt.Println("Database  not found")
+		return nil
+	}
+	fmt.Printf("Title     %s\n", entry.Title)
+	if entry.Platform != "" {
+		fmt.Printf("  platform  %s\n", entry.Platform)
+	}
+	if entry.Quirks != "" {
+		fmt.Printf("  quirks    %s\n", entry.Quirks)
+	}
+	if entry.Tickrate > 0 {
+		fmt.Printf("  tickrate  %d instructions per frame\n", entry.Tickrate)
+	}
+	if len(entry.Colors) > 0 {
+		fmt.Printf("  colors    %s\n", strings.Join(entry.Colors, " "))
+	}
+	if len(entry.Keys) > 0 {
+		keys := make([]string, 0, len(entry.Keys))
+		for name, key := range entry.Keys {
+			keys = append(keys, name+"="+key)
+		}
+		sort.Strings(keys)
+		fmt.Printf("  keys      %s\n", strings.Join(keys, " "))
+	}
+	return nil
+}
+
+//testSuite runs tests of Timendus' test suite without a window, with the quirks profile of the configuration,
+//until their screens don't change, and writes the screen of every test. With --golden the screens are compared with the golden images. The tests are given by number in args, by default all of them but the keypad test.
+func testSuite(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	overrides := addSettings(flags)
+	suite := flags.String("suite", "assets/chip8-test-suite.ch8", "the ROM of the test suite")
+	cycles := flags.Uint64("cycles", 1000000, "the maximum number of cycles to execute for every test, until its screen doesn't change for 2 seconds")
+	golden := flags.String("golden", "", "compare the screens with the golden images of this directory, such as headless/testdata/suite, and report the checks that differ")
+	_ = flags.Parse(args)
+	cfg, err := overrides.load()
+	if err != nil {
+		return err
+	}
+	q, err := quirks(cfg)
+	if err != nil {
+		return err
+	}
+	rnd, err := random(cfg)
+	if err != nil {
+		return err
+	}
+
+	if *golden != "" && cfg.Quirks.Profile == "" {
+		return errors.New("the golden images are by quirks profile, give one with --quirks")
+	}
+
+	tests := []int{1, 2, 3, 4}
+	if flags.NArg() > 0 {
+		tests = nil
+		for _, arg := range flags.Args() {
+			test, err := strconv.Atoi(arg)
+			if err != nil || headless.SuiteTests[test] == "" {
+				return fmt.Errorf("'%s' is not a test of the suite, expected a number from 1 to %d", arg, len(headless.SuiteTests))
+			}
+			tests = append(tests, test)
+		}
+	}
+
+	failed := 0
+	for _, test := range tests {
+		runner, err := headless.NewRunner(q, nil, rnd)
+		if err != nil {
+			return err
+		}
+		if cfg.Speed.IPF > 0 {
+			runner.IPF = cfg.Speed.IPF
+		}
+		if err = loadFonts(runner.Chip8(), cfg); err != nil {
+			return err
+		}
+		if err = runner.Chip8().LoadROM(*suite); err != nil {
+			return err
+		}
+		runner.SelectSuiteTest(test, cfg.Quirks.Profile)
+		_, fault := runner.RunUntilStable(headless.SuiteStableFrames, *cycles)
+
+		fmt.Printf("%d. %s (%s)\n", test, headless.SuiteTests[test], cfg.Quirks.Profile)
+		if err = runner.WriteASCII(os.Stdout); err != nil {
+			return err
+		}
+		if fault != nil {
+			return fault
+		}
+		if *golden != "" {
+			names, err := compareGolden(runner, test, filepath.Join(*golden, strings.ToLower(cfg.Quirks.Profile), fmt.Sprintf("%d.png", test)))
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Printf("FAIL %s\n", name)
+			}
+			failed += len(names)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d checks differ from the golden images", failed)
+	}
+	return nil
+}
+
+//recordGolden runs the ROM given in args without a window, pressing the keys of a script, and writes a golden trace of the run
+func recordGolden(args []string) error {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	overrides := addSettings(flags)
+	cycles := flags.Uint64("cycles", 6000, "the number of cycles to record")
+	keys := flags.String("keys", "", "a script of key events, with lines 'cycle down|up key'")
+	output := flags.String("o", "", "the file of the trace (default the name of the ROM with the extension .golden)")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8 record [--keys script] [--cycles N] [-o trace.golden] [flags] rom.ch8")
+	}
+	cfg, err := overrides.loadROM(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	events, err := readKeys(*keys)
+	if err != nil {
+		return err
+	}
+	header := golden.Header{Profile: cfg.Quirks.Profile, IPF: chip8.DefaultIPF, Cycles: *cycles, Random: cfg.Random.Source, Events: events}
+	if cfg.Speed.IPF > 0 {
+		header.IPF = cfg.Speed.IPF
+	}
+	rnd, err := random(cfg)
+	if err != nil {
+		return err
+	}
+	runner, err := goldenRunner(cfg, header, rnd)
+	if err != nil {
+		return err
+	}
+	if header.ROM, err = hashROM(cfg.Paths.Rom); err != nil {
+		return err
+	}
+	t, err := golden.Record(runner, header)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(cfg.Paths.Rom, filepath.Ext(cfg.Paths.Rom)) + ".golden"
+	}
+	if err = t.Create(*output); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d cycles, %d frames\n", *output, len(t.Steps), len(t.Frames))
+	return nil
+}
+
+//verifyGolden runs the ROM given in args like the golden trace given in args, with its quirks profile, its speed, its random numbers and its keys,
+//and reports the first cycle in which the state of the chip8 differs from the trace
+func verifyGolden(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	files := addConfigFiles(flags)
+	font := flags.String("font", "", "the font file, if the trace was recorded with one")
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		return errors.New("usage: chip8 verify [--font font.bin] trace.golden rom.ch8")
+	}
+	cfg, err := files.read()
+	if err != nil {
+		return err
+	}
+	t, err := golden.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	cfg.Paths.Rom, cfg.Paths.Fonts, cfg.Quirks.Profile = flags.Arg(1), *font, t.Profile
+	hash, err := hashROM(cfg.Paths.Rom)
+	if err != nil {
+		return err
+	}
+	if hash != t.ROM {
+		return fmt.Errorf("the trace was recorded with another ROM, whose SHA-1 is %s", t.ROM)
+	}
+	rnd, err := t.RandomOption()
+	if err != nil {
+		return err
+	}
+	runner, err := goldenRunner(cfg, t.Header, rnd)
+	if err != nil {
+		return err
+	}
+	if err = golden.Verify(runner, t); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d cycles, %d frames as expected\n", flags.Arg(1), len(t.Steps), len(t.Frames))
+	return nil
+}
+
+//goldenRunner instantiates a runner with the settings of the header and the random source rnd, and loads into its chip8 the fonts and the ROM of the configuration
+func goldenRunner(cfg config.Config, h golden.Header, rnd chip8.Option) (*headless.Runner, error) {
+	cfg.Quirks.Profile = h.Profile
+	q, err := quirks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := headless.NewRunner(q, h.Events, rnd)
+	if err != nil {
+		return nil, err
+	}
+	return runner, prepare(runner.Chip8(), cfg, "")
+}
+
+//openMovie reads the movie of the file filename, checks that it was recorded with the ROM of the configuration,
+//and sets the quirks profile and the speed of the configuration to the ones of the movie. The random numbers are the ones of Movie.RandomOption.
+func openMovie(filename string, cfg *config.Config) (*movie.Movie, error) {
+	m, err := movie.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := hashROM(cfg.Paths.Rom)
+	if err != nil {
+		return nil, err
+	}
+	if hash != m.ROM {
+		return nil, fmt.Errorf("the movie was recorded with another ROM, whose SHA-1 is %s", m.ROM)
+	}
+	cfg.Quirks.Profile, cfg.Speed.IPF = m.Profile, m.IPF
+	return m, nil
+}
+
+//hashROM returns the SHA-1 of the ROM file filename, as romdb.Hash returns it
+func hashROM(filename string) (string, error) {
+	rom, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return romdb.Hash(rom), nil
+}
+
+//readKeys reads the script of key events of the file filename, there aren't any events if filename is empty
+func readKeys(filename string) ([]headless.KeyEvent, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return headless.ParseKeyEvents(f)
+}
+
+//compareGolden compares the screen of a test of the suite with its golden image, and returns the names of the checks that differ
+func compareGolden(runner *headless.Runner, test int, filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	want, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	fb := runner.Chip8().GetFrameBuffer()
+	return headless.FailedChecks(test, fb.Image(monitor.DefaultPalette), want), nil
+}
+
+//disassemble writes the assembly of the ROM given in args to the standard output
+func disassemble(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: chip8 disasm rom.ch8")
+	}
+	rom, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	_, err = disasm.Disassemble(rom).WriteTo(os.Stdout)
+	return err
+}
+
+//assemble assembles the source given in args into a ROM, and writes its symbols next to it
+func assemble(args []string) error {
+	flags := flag.NewFlagSet("asm", flag.ExitOnError)
+	out := flags.String("o", "", "the ROM file, by default the source with the extension .ch8")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: chip8 asm [-o rom.ch8] source.asm")
+	}
+	source := flags.Arg(0)
+	if *out == "" {
+		*out = strings.TrimSuffix(source, filepath.Ext(source)) + ".ch8"
+	}
+
+	program, err := asm.Assemble(source)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(*out, program.ROM, 0644)
+	if err != nil {
+		return err
+	}
+	symbols, err := os.Create(strings.TrimSuffix(*out, filepath.Ext(*out)) + ".sym")
+	if err != nil {
+		return err
+	}
+	defer symbols.Close()
+	return program.WriteSymbols(symbols)
+}
+
+//runROM runs the ROM given in args in the window, with the rest of the configuration taken from the configuration file,
+//the entry of the ROM in the ROM database and the flags. Without a ROM in args it runs the ROM of the configuration.
+//With --terminal it runs the ROM in the terminal instead of the window.
+//With --headless it runs the ROM without a window for a number of cycles, and writes the final state of the chip8.
+func runROM(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	overrides := addSettings(flags)
+	isHeadless := flags.Bool("headless", false, "run without a window")
+	isTerminal := flags.Bool("terminal", false, "run in the terminal, for example over SSH")
+	loadState := flags.String("load-state", "", "continue the program from a save state of the ROM")
+	playMovie := flags.String("movie", "", "play a movie of the ROM recorded with --record-movie, in the window or with --headless")
+	recordMovie := flags.String("record-movie", "", "window: record a movie of the keys pressed into this file")
+	cycles := flags.Uint64("cycles", 1000, "headless: the number of cycles to execute")
+	keys := flags.String("keys", "", "headless: a script of key events, with lines 'cycle down|up key'")
+	ascii := flags.String("ascii", "", "headless: write the frame buffer as text to this file, - for the standard output (default if there isn't any other output)")
+	png := flags.String("png", "", "headless: write the frame buffer as a PNG image to this file")
+	state := flags.String("state", "", "headless: write the state of the chip8 as JSON to this file")
+	saveState := flags.String("save-state", "", "headless: write a save state of the chip8 to this file")
+	record := flags.String("record", "", "headless: record the display into this animated .gif or .png file")
+	wav := flags.String("wav", "", "headless: render the audio into this WAV file")
+	recordScale := flags.Int("record-scale", 0, "headless: side in pixels of a pixel of the 64x32 display in the recording (default the one of the configuration)")
+	terminalMode := flags.String("terminal-mode", "halfblocks", "terminal: draw the pixels with halfblocks or braille characters")
+	sidebar := flags.Bool("sidebar", false, "terminal: show the registers and the next instructions next to the screen")
+	bell := flags.Bool("bell", false, "terminal: ring the bell of the terminal when the chip8 beeps")
+	var traceOptions trace.Options
+	flags.StringVar(&traceOptions.File, "trace", "", "write a trace of the instructions executed to this file")
+	flags.StringVar(&traceOptions.Format, "trace-format", "text", "format of the trace: text or ndjson")
+	flags.StringVar(&traceOptions.PC, "trace-pc", "", "trace only the instructions in this hexadecimal range of addresses, such as 200-2FF")
+	flags.StringVar(&traceOptions.Opcodes, "trace-ops", "", "trace only these classes of opcodes (their first hexadecimal digit), such as 8,D")
+	flags.StringVar(&traceOptions.Cycles, "trace-cycles", "", "trace only the cycles in this range, such as 1000-2000 or 1000-")
+	flags.StringVar(&traceOptions.MaxSize, "trace-max-size", "", "rotate the trace file when it reaches this size, such as 10M")
+	flags.IntVar(&traceOptions.Files, "trace-files", trace.DefaultFiles, "number of old trace files kept when it's rotated")
+	_ = flags.Parse(args)
+	if flags.NArg() > 1 {
+		return errors.New("usage: chip8 run [--headless --cycles N | --terminal] [flags] [rom.ch8]")
+	}
+
+	cfg, err := overrides.loadROM(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if cfg.Paths.Rom == "" {
+		return errors.New("there isn't a ROM to run, give one with chip8 run rom.ch8 or in the configuration")
+	}
+	if traceOptions.File != "" {
+		cfg.Trace = config.Trace(traceOptions)
+	}
+	var play *movie.Movie
+	if *playMovie != "" || *recordMovie != "" {
+		if *loadState != "" || *isTerminal || cfg.Debug.On == "true" {
+			return errors.New("the movies start from the ROM, they can't be used with a save state, the terminal or the debugger")
+		}
+		if *recordMovie != "" && (*isHeadless || *playMovie != "") {
+			return errors.New("the movies are recorded in the window, give keys to the headless mode with --keys")
+		}
+	}
+	if *playMovie != "" {
+		if play, err = openMovie(*playMovie, &cfg); err != nil {
+			return err
+		}
+	}
+
+	if *isTerminal {
+		mode, err := terminal.ParseMode(*terminalMode)
+		if err != nil {
+			return err
+		}
+		return runTerminal(cfg, *loadState, terminal.Options{Mode: mode, Sidebar: *sidebar, Bell: *bell})
+	}
+	if !*isHeadless {
+		return runWindow(cfg, *loadState, play, *recordMovie)
+	}
+
+	q, err := quirks(cfg)
+	if err != nil {
+		return err
+	}
+	rnd, err := random(cfg)
+	if err != nil {
+		return err
+	}
+	events, err := readKeys(*keys)
+	if err != nil {
+		return err
+	}
+	if play != nil {
+		if *keys != "" {
+			return errors.New("the keys of a movie can't be replaced by a script of key events")
+		}
+		events = play.Events()
+		if rnd, err = play.RandomOption(); err != nil {
+			return err
+		}
+		if !overrides.given()["cycles"] {
+			*cycles = play.Cycles()
+		}
+	}
+
+	runner, err := headless.NewRunner(q, events, rnd)
+	if err != nil {
+		return err
+	}
+	if cfg.Speed.IPF > 0 {
+		runner.IPF = cfg.Speed.IPF
+	}
+	if runner.Palette, err = monitor.ParsePalette(cfg.Display.Palette, cfg.Display.Colors); err != nil {
+		return err
+	}
+	if err = prepare(runner.Chip8(), cfg, *loadState); err != nil {
+		return err
+	}
+	tracer, err := openTrace(runner.Chip8(), cfg)
+	if err != nil {
+		return err
+	}
+	var rec *recorder.Recorder
+	if *record != "" {
+		if *recordScale > 0 {
+			cfg.Record.Scale = *recordScale
+		}
+		if rec, err = recorder.Create(*record, runner.Palette, cfg.Record.Scale); err != nil {
+			return err
+		}
+	}
+	var sound *audio.WAV
+	var renderSound func()
+	if *wav != "" {
+		if sound, renderSound, err = createWAV(*wav, runner.Chip8(), cfg); err != nil {
+			return err
+		}
+	}
+	runner.Frame = func() {
+		if rec != nil {
+			rec.Frame(runner.Chip8().GetFrameBuffer())
+		}
+		if sound != nil {
+			renderSound()
+		}
+	}
+	fault := runner.Run(*cycles)
+	if tracer != nil {
+		if err = tracer.Close(); err != nil {
+			return err
+		}
+	}
+	if rec != nil {
+		if err = rec.Close(); err != nil {
+			return err
+		}
+	}
+	if sound != nil {
+		if err = sound.Close(); err != nil {
+			return err
+		}
+	}
+
+	if *ascii == "" && *png == "" && *state == "" && *saveState == "" && *record == "" && *wav == "" {
+		*ascii = "-"
+	}
+	outputs := []struct {
+		filename string
+		write    func(w io.Writer) error
+	}{
+		{*ascii, runner.WriteASCII},
+		{*png, runner.WritePNG},
+		{*state, runner.WriteState},
+		{*saveState, runner.Chip8().SaveState},
+	}
+	for _, output := range outputs {
+		if err = writeOutput(output.filename, output.write); err != nil {
+			return err
+		}
+	}
+	return fault
+}
+
+//createWAV creates a WAV file for the audio of the chip8: the beep synthesized with the audio settings of the configuration,
+//or the audio pattern if the quirks profile is xochip. It returns the WAV and the function which renders a frame into it,
+//which must be called at the end of every frame before ticking the timers.
+func createWAV(filename string, c8 *chip8.Chip8, cfg config.Config) (*audio.WAV, func(), error) {
+	if strings.EqualFold(cfg.Quirks.Profile, "xochip") {
+		pattern := audio.NewPatternStreamer(c8, app.SampleRate)
+		wav, err := audio.CreateWAV(filename, pattern, app.SampleRate)
+		if err != nil {
+			return nil, nil, err
+		}
+		return wav, func() {
+			pattern.Frame(c8.MustBeep())
+			wav.Frame()
+		}, nil
+	}
+	voice, err := audio.ParseVoice(cfg.Audio.Pitch, cfg.Audio.Volume, cfg.Audio.Waveform)
+	if err != nil {
+		return nil, nil, err
+	}
+	buzzer := audio.NewBuzzer(voice, app.SampleRate)
+	wav, err := audio.CreateWAV(filename, buzzer, app.SampleRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wav, func() {
+		buzzer.Frame(c8.MustBeep())
+		wav.Frame()
+	}, nil
+}
+
+//runTerminal runs the ROM of the configuration in the terminal of the standard input and output, like the window runs it,
+//with the palette, the keys and the speed of the configuration added to the options
+func runTerminal(cfg config.Config, loadState string, o terminal.Options) error {
+	q, err := quirks(cfg)
+	if err != nil {
+		return err
+	}
+	rnd, err := random(cfg)
+	if err != nil {
+		return err
+	}
+	if o.Palette, err = monitor.ParsePalette(cfg.Display.Palette, cfg.Display.Colors); err != nil {
+		return err
+	}
+	if o.Keys, err = terminal.ParseKeyMap(cfg.Keys); err != nil {
+		return err
+	}
+	o.IPF = cfg.Speed.IPF
+	keypad := &chip8.KeypadState{}
+	c8, err := chip8.NewChip8(keypad, q, rnd)
+	if err != nil {
+		return err
+	}
+	if err = prepare(c8, cfg, loadState); err != nil {
+		return err
+	}
+	if cfg.Paths.Flags != "" {
+		if err = c8.LoadFlags(cfg.Paths.Flags); err != nil {
+			return err
+		}
+	}
+	tracer, err := openTrace(c8, cfg)
+	if err != nil {
+		return err
+	}
+	fault := terminal.Run(c8, keypad, os.Stdin, os.Stdout, o)
+	if tracer != nil {
+		if err = tracer.Close(); err != nil {
+			return err
+		}
+	}
+	return fault
+}
+
+//prepare loads into the chip8 the fonts and the ROM of the configuration, and the save state if it isn't empty
+func prepare(c8 *chip8.Chip8, cfg config.Config, loadState string) error {
+	if err := loadFonts(c8, cfg); err != nil {
+		return err
+	}
+	if err := c8.LoadROM(cfg.Paths.Rom); err != nil {
+		return err
+	}
+	if loadState == "" {
+		return nil
+	}
+	f, err := os.Open(loadState)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c8.LoadState(f)
+}
+
+//openTrace attaches to the chip8 a tracer which writes to the trace file of the configuration, if it has one
+func openTrace(c8 *chip8.Chip8, cfg config.Config) (*trace.Tracer, error) {
+	if cfg.Trace.File == "" {
+		return nil, nil
+	}
+	tracer, err := trace.Open(trace.Options(cfg.Trace))
+	if err != nil {
+		return nil, err
+	}
+	tracer.Attach(c8)
+	return tracer, nil
+}
+
+//writeOutput calls write with the file filename, or the standard output if filename is -. It doesn't do anything if filename is empty.
+func writeOutput(filename string, write func(w io.Writer) error) error {
+	switch filename {
+	case "":
+		return nil
+	case "-":
+		return write(os.Stdout)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -2,23 +2,76 @@ package config
 
 type Config struct {
 	Paths struct {
-		Beep  string `yaml:"beep"`
-		Rom   string `yaml:"rom"`
-		Fonts string `yaml:"fonts"`
+		Beep     string `yaml:"beep"`
+		Rom      string `yaml:"rom"`
+		Fonts    string `yaml:"fonts"`
+		Flags    string `yaml:"flags"`
+		States   string `yaml:"states"`
+		ROMDB    string `yaml:"romdb"`    //ROM database added to the built-in one
+		Captures string `yaml:"captures"` //Directory of the screenshots and the recordings taken in the window
 	} `yaml:"paths"`
 
+	Quirks struct {
+		Profile string `yaml:"profile"`
+	} `yaml:"quirks"`
+
+	Speed struct {
+		IPF int `yaml:"ipf"`
+	} `yaml:"speed"`
+
+	Random struct {
+		Source string `yaml:"source"` //Generator of the random numbers of CXKK: prng, vip or sequence
+		Seed   string `yaml:"seed"`   //Seed of the generator, a number or clock for a different seed on every run
+		File   string `yaml:"file"`   //File of the hexadecimal bytes of the sequence source, or dump of the interpreter of the vip source
+	} `yaml:"random"`
+
+	Display struct {
+		Scale    int      `yaml:"scale"`    //Side in pixels of a pixel of the 64x32 display
+		Palette  string   `yaml:"palette"`  //Preset of the colours: classic, amber, green or lcd
+		Colors   []string `yaml:"colors"`   //Colours replacing the ones of the preset, such as #FF8800: background, first plane, second plane and both planes
+		Grid     float64  `yaml:"grid"`     //Gap in pixels between the pixels of the display, 0 for none
+		Phosphor float64  `yaml:"phosphor"` //Persistence of the pixels turned off, from 0 (none) to 1, which fade out over the next frames
+	} `yaml:"display"`
+
+	Audio struct {
+		Pitch    float64 `yaml:"pitch"`    //Frequency of the beep in Hz
+		Volume   float64 `yaml:"volume"`   //Amplitude of the beep, from 0 to 1
+		Waveform string  `yaml:"waveform"` //Shape of the wave of the beep: square, triangle, sawtooth or sine
+	} `yaml:"audio"`
+
+	Keys map[string]string `yaml:"keys"` //Keys of the keyboard mapped to keys of the keypad, over the default layout
+
+	Record struct {
+		Scale  int    `yaml:"scale"`  //Side in pixels of a pixel of the 64x32 display in the screenshots and the recordings
+		Format string `yaml:"format"` //Format of the recordings taken in the window: gif or apng
+	} `yaml:"record"`
+
+	Rewind struct {
+		Seconds int `yaml:"seconds"`
+	} `yaml:"rewind"`
+
+	Trace Trace `yaml:"trace"`
+
 	Debug struct {
 		On   string `yaml:"on"`
 		File string `yaml:"file"`
 	} `yaml:"debug"`
 
 	Test struct {
-		ExpectedStateROM1 string `yaml:"expectedStateROM1"`
-		ExpectedStateROM2 string `yaml:"expectedStateROM2"`
-		ExpectedStateROM3 string `yaml:"expectedStateROM3"`
-		ROM1              string `yaml:"ROM1"`
-		ROM2              string `yaml:"ROM2"`
-		ROM3              string `yaml:"ROM3"`
-		FONT              string `yaml:"FONT"`
+		ROM1 string `yaml:"ROM1"`
+		ROM2 string `yaml:"ROM2"`
+		ROM3 string `yaml:"ROM3"`
+		FONT string `yaml:"FONT"`
 	} `yaml:"test"`
 }
+
+//Trace is the trace of the instructions executed by the chip8, it has the fields of trace.Options
+type Trace struct {
+	File    string `yaml:"file"`
+	Format  string `yaml:"format"`
+	PC      string `yaml:"pc"`
+	Opcodes string `yaml:"opcodes"`
+	Cycles  string `yaml:"cycles"`
+	MaxSize string `yaml:"maxSize"`
+	Files   int    `yaml:"files"`
+}
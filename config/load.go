@@ -0,0 +1,40 @@
+package config
+
+import (
+	"gopkg.in/yaml.v2"
+	"os"
+)
+
+//DefaultScale is the side in pixels of a pixel of the 64x32 display when the configuration doesn't give one
+const DefaultScale = 16
+
+//Default returns the configuration used when there isn't a configuration file, and the values of the settings missing in it.
+//There isn't a ROM, the built-in fonts and a beep synthesized as a square wave are used instead of files, and the random numbers are seeded with the clock.
+func Default() Config {
+	var cfg Config
+	cfg.Speed.IPF = 8
+	cfg.Random.Source = "prng"
+	cfg.Random.Seed = "clock"
+	cfg.Display.Scale = DefaultScale
+	cfg.Audio.Pitch = 440
+	cfg.Audio.Volume = 0.2
+	cfg.Audio.Waveform = "square"
+	cfg.Record.Scale = 4
+	cfg.Record.Format = "gif"
+	cfg.Rewind.Seconds = 10
+	cfg.Debug.On = "false"
+	return cfg
+}
+
+//Load reads the configuration file filename over the default configuration, so the settings missing in it keep their default values.
+//If the file doesn't exist, the error satisfies errors.Is(err, os.ErrNotExist).
+func Load(filename string) (Config, error) {
+	cfg := Default()
+	f, err := os.Open(filename)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+	err = yaml.NewDecoder(f).Decode(&cfg)
+	return cfg, err
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	yml := "paths:\n  rom: \"game.ch8\"\nspeed:\n  ipf: 20\n"
+	assert.NoError(t, ioutil.WriteFile(filename, []byte(yml), 0644), "")
+
+	cfg, err := Load(filename)
+	assert.NoError(t, err, "")
+	assert.Equal(t, "game.ch8", cfg.Paths.Rom, "")
+	assert.Equal(t, 20, cfg.Speed.IPF, "")
+	assert.Equal(t, "", cfg.Paths.Fonts, "the built-in fonts by default")
+	assert.Equal(t, "", cfg.Quirks.Profile, "missing settings keep the default")
+	assert.Equal(t, DefaultScale, cfg.Display.Scale, "")
+
+	cfg, err = Load(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.True(t, errors.Is(err, os.ErrNotExist), "")
+	assert.Equal(t, Default(), cfg, "")
+
+	cfg, err = Load("../config.yml")
+	assert.NoError(t, err, "the configuration of the repository")
+	assert.Equal(t, "../Chip-8/assets/PONG.ch8", cfg.Paths.Rom, "")
+}
@@ -0,0 +1,206 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"strconv"
+	"strings"
+)
+
+//Breakpoint stops the execution of the chip8 when it's hit
+type Breakpoint interface {
+	String() string
+}
+
+//checker is implemented by the breakpoints which are checked before every instruction,
+//with the registers of the chip8 and the opcode it's going to execute
+type checker interface {
+	check(regs chip8.Registers, opcode uint16) bool
+}
+
+//watcher is implemented by the breakpoints which are hit by the memory accesses of the instructions
+type watcher interface {
+	watch(addr uint16, n int, write bool) bool
+}
+
+//pcBreakpoint stops before executing the instruction at addr
+type pcBreakpoint struct {
+	addr uint16
+}
+
+func (b *pcBreakpoint) check(regs chip8.Registers, opcode uint16) bool {
+	return regs.PC == b.addr
+}
+
+func (b *pcBreakpoint) String() string {
+	return fmt.Sprintf("breakpoint at %03X", b.addr)
+}
+
+//opcodeBreakpoint stops before executing an opcode which matches a pattern such as "8XY6" or "DXYN".
+//The hexadecimal digits of the pattern must be equal to the ones of the opcode, the rest of the characters match any digit.
+type opcodeBreakpoint struct {
+	pattern string
+	mask    uint16
+	value   uint16
+}
+
+func (b *opcodeBreakpoint) check(regs chip8.Registers, opcode uint16) bool {
+	return opcode&b.mask == b.value
+}
+
+func (b *opcodeBreakpoint) String() string {
+	return "breakpoint on opcode " + b.pattern
+}
+
+//conditionBreakpoint stops when a condition over a register becomes true, such as "V3 == 10" or "I >= 300".
+//It only stops when the condition changes from false to true, so the program can continue while it stays true.
+type conditionBreakpoint struct {
+	register string
+	operator string
+	value    uint16
+	wasTrue  bool
+}
+
+func (b *conditionBreakpoint) check(regs chip8.Registers, opcode uint16) bool {
+	isTrue := compare(registerValue(regs, b.register), b.operator, b.value)
+	hit := isTrue && !b.wasTrue
+	b.wasTrue = isTrue
+	return hit
+}
+
+func (b *conditionBreakpoint) String() string {
+	return fmt.Sprintf("breakpoint when %s %s %X", b.register, b.operator, b.value)
+}
+
+//watchpoint stops after an instruction reads or writes the byte at addr
+type watchpoint struct {
+	addr  uint16
+	read  bool
+	write bool
+}
+
+func (b *watchpoint) watch(addr uint16, n int, write bool) bool {
+	if b.addr < addr || int(b.addr) >= int(addr)+n {
+		return false
+	}
+	return (write && b.write) || (!write && b.read)
+}
+
+func (b *watchpoint) String() string {
+	access := "read/write"
+	if !b.write {
+		access = "read"
+	} else if !b.read {
+		access = "write"
+	}
+	return fmt.Sprintf("watchpoint on %s of %03X", access, b.addr)
+}
+
+//NewPCBreakpoint returns a breakpoint which stops before executing the instruction at addr
+func NewPCBreakpoint(addr uint16) Breakpoint {
+	return &pcBreakpoint{addr: addr}
+}
+
+//NewOpcodeBreakpoint returns a breakpoint which stops before executing an opcode that matches pattern, for example "8XY6", "00EE" or "FX..".
+func NewOpcodeBreakpoint(pattern string) (Breakpoint, error) {
+	pattern = strings.ToUpper(pattern)
+	if len(pattern) != 4 {
+		return nil, errors.New("the opcode pattern '" + pattern + "' must have 4 characters")
+	}
+	b := &opcodeBreakpoint{pattern: pattern}
+	for k := 0; k < 4; k++ {
+		digit, err := strconv.ParseUint(pattern[k:k+1], 16, 4)
+		if err != nil {
+			continue
+		}
+		shift := uint(12 - 4*k)
+		b.mask |= 0xF << shift
+		b.value |= uint16(digit) << shift
+	}
+	return b, nil
+}
+
+//NewConditionBreakpoint returns a breakpoint which stops when the condition "register operator value" becomes true.
+//register can be V0 to VF, I, PC, SP, DT or ST, operator can be ==, !=, <, <=, > or >=, and value is hexadecimal.
+func NewConditionBreakpoint(register string, operator string, value string) (Breakpoint, error) {
+	register = strings.ToUpper(register)
+	if _, ok := registerNames[register]; !ok {
+		return nil, errors.New("unknown register '" + register + "'")
+	}
+	switch operator {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, errors.New("unknown operator '" + operator + "'")
+	}
+	v, err := ParseNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	return &conditionBreakpoint{register: register, operator: operator, value: v}, nil
+}
+
+//NewWatchpoint returns a breakpoint which stops after an instruction accesses the byte at addr.
+//access can be "r" for reads, "w" for writes or "rw" for both
+func NewWatchpoint(addr uint16, access string) (Breakpoint, error) {
+	switch access {
+	case "r":
+		return &watchpoint{addr: addr, read: true}, nil
+	case "w":
+		return &watchpoint{addr: addr, write: true}, nil
+	case "rw", "":
+		return &watchpoint{addr: addr, read: true, write: true}, nil
+	}
+	return nil, errors.New("unknown access '" + access + "', expected r, w or rw")
+}
+
+//ParseNumber parses a hexadecimal number, with or without the 0x prefix
+func ParseNumber(s string) (uint16, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 16)
+	if err != nil {
+		return 0, errors.New("'" + s + "' is not a hexadecimal number")
+	}
+	return uint16(n), nil
+}
+
+var registerNames = map[string]bool{
+	"V0": true, "V1": true, "V2": true, "V3": true, "V4": true, "V5": true, "V6": true, "V7": true,
+	"V8": true, "V9": true, "VA": true, "VB": true, "VC": true, "VD": true, "VE": true, "VF": true,
+	"I": true, "PC": true, "SP": true, "DT": true, "ST": true,
+}
+
+//registerValue returns the value of the register with the given name
+func registerValue(regs chip8.Registers, register string) uint16 {
+	switch register {
+	case "I":
+		return regs.I
+	case "PC":
+		return regs.PC
+	case "SP":
+		return uint16(regs.SP)
+	case "DT":
+		return uint16(regs.DelayTimer)
+	case "ST":
+		return uint16(regs.SoundTimer)
+	}
+	k, _ := strconv.ParseUint(register[1:], 16, 4)
+	return uint16(regs.V[k])
+}
+
+func compare(a uint16, operator string, b uint16) bool {
+	switch operator {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
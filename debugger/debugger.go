@@ -0,0 +1,316 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/disasm"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//mode is what the debugger is doing with the chip8
+type mode int
+
+const (
+	paused   mode = iota
+	running       //Runs until a breakpoint is hit
+	stepping      //Runs a single instruction
+	stepOver      //Runs until the subroutine called by the current instruction returns
+	stepOut       //Runs until the current subroutine returns
+)
+
+//Debugger controls the execution of a chip8. The app must call Cycle instead of chip8.Cycle,
+//so it can stop the chip8 on the breakpoints and execute it step by step.
+//All the methods can be called from different goroutines, like the one that cycles the chip8 and the one that reads the commands.
+type Debugger struct {
+	c8 *chip8.Chip8
+	mu sync.Mutex
+
+	breakpoints map[int]Breakpoint
+	nextID      int
+
+	mode       mode
+	target     byte   //Stack pointer at which stepOver and stepOut stop
+	returnAddr uint16 //Address at which stepOver stops
+	cycles     uint64 //Number of instructions executed by the chip8 when the step began
+	skipBreak  bool   //The breakpoints of the current instruction are not checked when the execution is resumed from it
+	watchHit   int    //Id of the watchpoint hit by the current instruction, 0 if none
+
+	reason string       //Why the chip8 is paused
+	dirty  bool         //The state changed since the last call to Dirty
+	notify func(string) //Called every time the chip8 is paused
+}
+
+//New instantiates a Debugger for c8. The chip8 starts paused, so breakpoints can be set before the program runs.
+func New(c8 *chip8.Chip8) *Debugger {
+	d := &Debugger{
+		c8:          c8,
+		breakpoints: map[int]Breakpoint{},
+		nextID:      1,
+		mode:        paused,
+		reason:      "start",
+		dirty:       true,
+	}
+	c8.SetMemoryHook(d.memoryHook)
+	return d
+}
+
+//memoryHook is called by the chip8 on every memory access, to check the watchpoints
+func (d *Debugger) memoryHook(addr uint16, n int, write bool) {
+	if d.watchHit != 0 {
+		return
+	}
+	for _, id := range d.ids() {
+		if w, ok := d.breakpoints[id].(watcher); ok && w.watch(addr, n, write) {
+			d.watchHit = id
+			return
+		}
+	}
+}
+
+//Cycle executes an instruction of the chip8, unless it's paused or a breakpoint is hit before executing it.
+//If the chip8 fails, it's paused and the error is returned.
+func (d *Debugger) Cycle() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.mode == paused {
+		return nil
+	}
+
+	regs := d.c8.Registers()
+	if !d.skipBreak {
+		opcode := d.c8.PeekOpcode()
+		for _, id := range d.ids() {
+			if c, ok := d.breakpoints[id].(checker); ok && c.check(regs, opcode) {
+				d.pause(fmt.Sprintf("%s (#%d)", d.breakpoints[id], id))
+				return nil
+			}
+		}
+	}
+	d.skipBreak = false
+
+	d.watchHit = 0
+	if err := d.c8.Cycle(); err != nil {
+		d.pause(err.Error())
+		return err
+	}
+	if d.watchHit != 0 {
+		d.pause(fmt.Sprintf("%s (#%d) hit at %03X", d.breakpoints[d.watchHit], d.watchHit, regs.PC))
+		return nil
+	}
+
+	executed := d.c8.Cycles() > d.cycles
+	regs = d.c8.Registers()
+	switch {
+	case d.mode == stepping && executed:
+		d.pause("step")
+	case d.mode == stepOver && regs.SP == d.target && regs.PC == d.returnAddr:
+		d.pause("step over")
+	case d.mode == stepOut && regs.SP < d.target:
+		d.pause("step out")
+	}
+	return nil
+}
+
+//pause stops the chip8, reason is shown to the user
+func (d *Debugger) pause(reason string) {
+	d.mode = paused
+	d.reason = reason
+	d.dirty = true
+	if d.notify != nil {
+		d.notify(reason)
+	}
+}
+
+//resume starts running the chip8 in the given mode
+func (d *Debugger) resume(m mode) {
+	d.mode = m
+	d.reason = ""
+	d.dirty = true
+	d.skipBreak = true
+	d.cycles = d.c8.Cycles()
+}
+
+//Continue runs the chip8 until a breakpoint is hit
+func (d *Debugger) Continue() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resume(running)
+}
+
+//Pause stops the chip8
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mode != paused {
+		d.pause("paused")
+	}
+}
+
+//Paused reports if the chip8 is paused
+func (d *Debugger) Paused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.mode == paused
+}
+
+//Step executes a single instruction
+func (d *Debugger) Step() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resume(stepping)
+}
+
+//StepOver executes a single instruction, but if it's a call (2NNN) it runs until the subroutine returns
+func (d *Debugger) StepOver() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.c8.PeekOpcode()&0xF000 != 0x2000 {
+		d.resume(stepping)
+		return
+	}
+	regs := d.c8.Registers()
+	d.target = regs.SP
+	d.returnAddr = regs.PC + 2
+	d.resume(stepOver)
+}
+
+//StepOut runs until the current subroutine returns (00EE)
+func (d *Debugger) StepOut() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	regs := d.c8.Registers()
+	if regs.SP == 0 {
+		return errors.New("the chip8 is not running a subroutine")
+	}
+	d.target = regs.SP
+	d.resume(stepOut)
+	return nil
+}
+
+//Add adds a breakpoint and returns its id
+func (d *Debugger) Add(b Breakpoint) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := d.nextID
+	d.nextID++
+	d.breakpoints[id] = b
+	d.dirty = true
+	return id
+}
+
+//Delete removes the breakpoint with the given id
+func (d *Debugger) Delete(id int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.breakpoints[id]; !ok {
+		return fmt.Errorf("there is no breakpoint #%d", id)
+	}
+	delete(d.breakpoints, id)
+	d.dirty = true
+	return nil
+}
+
+//ToggleBreakpoint adds a breakpoint at the current address of the program counter, or removes it if there is already one
+func (d *Debugger) ToggleBreakpoint() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pc := d.c8.Registers().PC
+	for id, b := range d.breakpoints {
+		if pcb, ok := b.(*pcBreakpoint); ok && pcb.addr == pc {
+			delete(d.breakpoints, id)
+			d.dirty = true
+			return
+		}
+	}
+	d.breakpoints[d.nextID] = NewPCBreakpoint(pc)
+	d.nextID++
+	d.dirty = true
+}
+
+//Breakpoints returns a description of every breakpoint, with its id
+func (d *Debugger) Breakpoints() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var list []string
+	for _, id := range d.ids() {
+		list = append(list, fmt.Sprintf("#%d %s", id, d.breakpoints[id]))
+	}
+	return list
+}
+
+//ids returns the ids of the breakpoints in the order they were added
+func (d *Debugger) ids() []int {
+	ids := make([]int, 0, len(d.breakpoints))
+	for id := range d.breakpoints {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+//Dirty reports if the state of the debugger or the chip8 changed since the last time it was called, so the overlay must be drawn again
+func (d *Debugger) Dirty() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dirty := d.dirty
+	d.dirty = false
+	return dirty
+}
+
+//Status describes whether the chip8 is running or paused, and the value of its registers
+func (d *Debugger) Status() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var sb strings.Builder
+	if d.mode == paused {
+		sb.WriteString("PAUSED: " + d.reason + "\n")
+	} else {
+		sb.WriteString("RUNNING\n")
+	}
+	regs := d.c8.Registers()
+	fmt.Fprintf(&sb, "PC %03X  OP %04X  I %03X  SP %X  %s\n", regs.PC, d.c8.PeekOpcode(), regs.I, regs.SP, d.mnemonic(regs.PC))
+	for k := 0; k < chip8.NumberOfRegisters; k++ {
+		fmt.Fprintf(&sb, "V%X %02X", k, regs.V[k])
+		if k%8 == 7 {
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString("  ")
+		}
+	}
+	fmt.Fprintf(&sb, "DT %02X  ST %02X", regs.DelayTimer, regs.SoundTimer)
+	if regs.SP > 0 {
+		sb.WriteString("\nSTACK")
+		for k := 0; k < int(regs.SP); k++ {
+			fmt.Fprintf(&sb, " %03X", regs.Stack[k])
+		}
+	}
+	return sb.String()
+}
+
+//mnemonic returns the assembly of the instruction at addr
+func (d *Debugger) mnemonic(addr uint16) string {
+	var bytes [4]byte
+	for k := range bytes {
+		bytes[k] = d.c8.ReadMemory(addr + uint16(k))
+	}
+	if in, ok := disasm.Decode(bytes[:]); ok {
+		return in.String()
+	}
+	return "???"
+}
+
+//Memory returns n bytes of the memory of the chip8 starting at addr
+func (d *Debugger) Memory(addr uint16, n int) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	bytes := make([]byte, 0, n)
+	for k := 0; k < n && int(addr)+k < chip8.AddressSpace; k++ {
+		bytes = append(bytes, d.c8.ReadMemory(addr+uint16(k)))
+	}
+	return bytes
+}
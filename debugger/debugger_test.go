@@ -0,0 +1,173 @@
+package debugger
+
+import (
+	"bytes"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//program is a small ROM with a subroutine that writes V0 into 300
+var program = []byte{
+	0x60, 0x05, //200: LD V0, 05
+	0x22, 0x08, //202: CALL 208
+	0x70, 0x01, //204: ADD V0, 01
+	0x12, 0x06, //206: JP 206
+	0xA3, 0x00, //208: LD I, 300
+	0xF0, 0x55, //20A: LD [I], V0
+	0x00, 0xEE, //20C: RET
+}
+
+func newDebugger(t *testing.T) *Debugger {
+	rom := filepath.Join(t.TempDir(), "program.ch8")
+	assert.NoError(t, ioutil.WriteFile(rom, program, 0644), "error writing the ROM")
+	c8, err := chip8.NewChip8(nil, chip8.Quirks{})
+	assert.NoError(t, err, "error in NewChip8")
+	assert.NoError(t, c8.LoadROM(rom), "error in LoadROM")
+	return New(c8)
+}
+
+//run cycles the debugger until it's paused
+func run(t *testing.T, d *Debugger) {
+	for k := 0; k < 100 && !d.Paused(); k++ {
+		assert.NoError(t, d.Cycle(), "error in Cycle")
+	}
+	assert.True(t, d.Paused(), "the debugger didn't pause")
+}
+
+func TestDebugger_StartsPaused(t *testing.T) {
+	d := newDebugger(t)
+	assert.True(t, d.Paused(), "paused at start")
+	assert.NoError(t, d.Cycle(), "")
+	assert.Equal(t, uint16(0x200), d.c8.Registers().PC, "nothing is executed while paused")
+}
+
+func TestDebugger_PCBreakpoint(t *testing.T) {
+	d := newDebugger(t)
+	d.Add(NewPCBreakpoint(0x20A))
+	d.Continue()
+	run(t, d)
+	assert.Equal(t, uint16(0x20A), d.c8.Registers().PC, "stops before 20A")
+
+	d.Continue()
+	d.Add(NewPCBreakpoint(0x206))
+	run(t, d)
+	assert.Equal(t, uint16(0x206), d.c8.Registers().PC, "resumes from the breakpoint")
+}
+
+func TestDebugger_OpcodeBreakpoint(t *testing.T) {
+	d := newDebugger(t)
+	b, err := NewOpcodeBreakpoint("fx55")
+	assert.NoError(t, err, "")
+	d.Add(b)
+	d.Continue()
+	run(t, d)
+	assert.Equal(t, uint16(0xF055), d.c8.PeekOpcode(), "FX55")
+
+	_, err = NewOpcodeBreakpoint("F55")
+	assert.Error(t, err, "too short")
+}
+
+func TestDebugger_ConditionBreakpoint(t *testing.T) {
+	d := newDebugger(t)
+	b, err := NewConditionBreakpoint("v0", ">=", "6")
+	assert.NoError(t, err, "")
+	d.Add(b)
+	d.Continue()
+	run(t, d)
+	regs := d.c8.Registers()
+	assert.Equal(t, byte(6), regs.V[0], "V0 >= 6")
+	assert.Equal(t, uint16(0x206), regs.PC, "after ADD V0, 01")
+
+	_, err = NewConditionBreakpoint("VG", "==", "1")
+	assert.Error(t, err, "unknown register")
+	_, err = NewConditionBreakpoint("V0", "=", "1")
+	assert.Error(t, err, "unknown operator")
+}
+
+func TestDebugger_Watchpoint(t *testing.T) {
+	d := newDebugger(t)
+	w, err := NewWatchpoint(0x300, "r")
+	assert.NoError(t, err, "")
+	d.Add(w)
+	w, err = NewWatchpoint(0x300, "w")
+	assert.NoError(t, err, "")
+	id := d.Add(w)
+	d.Continue()
+	run(t, d)
+	assert.Equal(t, uint16(0x20C), d.c8.Registers().PC, "stops after LD [I], V0")
+	assert.Equal(t, byte(5), d.c8.ReadMemory(0x300), "")
+	assert.Contains(t, d.Status(), "watchpoint on write of 300 (#2)", "")
+
+	assert.NoError(t, d.Delete(id), "")
+	assert.Error(t, d.Delete(id), "already deleted")
+}
+
+func TestDebugger_Step(t *testing.T) {
+	d := newDebugger(t)
+	d.Step()
+	run(t, d)
+	assert.Equal(t, uint16(0x202), d.c8.Registers().PC, "step")
+
+	d.StepOver()
+	run(t, d)
+	regs := d.c8.Registers()
+	assert.Equal(t, uint16(0x204), regs.PC, "step over the call")
+	assert.Equal(t, byte(0), regs.SP, "")
+
+	assert.Error(t, d.StepOut(), "not in a subroutine")
+}
+
+func TestDebugger_StepOut(t *testing.T) {
+	d := newDebugger(t)
+	d.Add(NewPCBreakpoint(0x208))
+	d.Continue()
+	run(t, d)
+	assert.Equal(t, byte(1), d.c8.Registers().SP, "inside the subroutine")
+
+	assert.NoError(t, d.StepOut(), "")
+	run(t, d)
+	assert.Equal(t, uint16(0x204), d.c8.Registers().PC, "step out")
+}
+
+func TestDebugger_ToggleBreakpoint(t *testing.T) {
+	d := newDebugger(t)
+	d.ToggleBreakpoint()
+	assert.Equal(t, []string{"#1 breakpoint at 200"}, d.Breakpoints(), "")
+	d.ToggleBreakpoint()
+	assert.Empty(t, d.Breakpoints(), "")
+}
+
+func TestREPL_Execute(t *testing.T) {
+	d := newDebugger(t)
+	var out bytes.Buffer
+	r := NewREPL(d, strings.NewReader(""), &out)
+
+	assert.NoError(t, r.Execute("b 20a"), "")
+	assert.NoError(t, r.Execute("bo 00EE"), "")
+	assert.NoError(t, r.Execute("bc VF != 0"), "")
+	assert.NoError(t, r.Execute("w 0x300 w"), "")
+	assert.Equal(t, []string{
+		"#1 breakpoint at 20A",
+		"#2 breakpoint on opcode 00EE",
+		"#3 breakpoint when VF != 0",
+		"#4 watchpoint on write of 300",
+	}, d.Breakpoints(), "")
+
+	assert.NoError(t, r.Execute("c"), "")
+	run(t, d)
+	assert.Contains(t, out.String(), "paused: breakpoint at 20A (#1)", "")
+
+	out.Reset()
+	assert.NoError(t, r.Execute("x 200 4"), "")
+	assert.Equal(t, "0200: 60 05 22 08\n", out.String(), "")
+
+	assert.NoError(t, r.Execute("d 1"), "")
+	assert.Error(t, r.Execute("d 1"), "already deleted")
+	assert.Error(t, r.Execute("b"), "missing address")
+	assert.Error(t, r.Execute("w 300 x"), "unknown access")
+	assert.Error(t, r.Execute("jump"), "unknown command")
+}
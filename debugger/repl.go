@@ -0,0 +1,177 @@
+package debugger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//Prompt is written by the REPL when it waits for a command
+const Prompt = "(chip8) "
+
+const help = `Commands (all the numbers are hexadecimal):
+  c, continue               run until a breakpoint is hit
+  p, pause                  pause the chip8
+  s, step                   execute a single instruction
+  n, next                   step over: execute a call (2NNN) until the subroutine returns
+  o, out                    step out: run until the current subroutine returns (00EE)
+  b, break <addr>           stop before executing the instruction at addr
+  bo <pattern>              stop before executing an opcode that matches pattern, e.g. 8XY6 or D...
+  bc <reg> <op> <value>     stop when a condition becomes true, e.g. V3 == 10 or I >= 300
+  w, watch <addr> [r|w|rw]  stop after an instruction reads and/or writes the byte at addr
+  l, list                   list the breakpoints
+  d, delete <id>            delete a breakpoint
+  r, regs                   show the registers
+  x <addr> [n]              show n bytes of memory starting at addr
+  h, help                   show this help`
+
+//REPL reads the commands of the debugger from in, and writes their output to out
+type REPL struct {
+	d   *Debugger
+	in  io.Reader
+	out io.Writer
+}
+
+//NewREPL instantiates a REPL for d. Every time the chip8 is paused, the REPL writes the reason to out
+func NewREPL(d *Debugger, in io.Reader, out io.Writer) *REPL {
+	r := &REPL{d: d, in: in, out: out}
+	d.mu.Lock()
+	d.notify = func(reason string) {
+		fmt.Fprintf(out, "\npaused: %s\n%s", reason, Prompt)
+	}
+	d.mu.Unlock()
+	return r
+}
+
+//Run executes the commands read from in until it's closed
+func (r *REPL) Run() {
+	scanner := bufio.NewScanner(r.in)
+	fmt.Fprint(r.out, Prompt)
+	for scanner.Scan() {
+		if err := r.Execute(scanner.Text()); err != nil {
+			fmt.Fprintln(r.out, "error:", err)
+		}
+		fmt.Fprint(r.out, Prompt)
+	}
+}
+
+//Execute executes a single command
+func (r *REPL) Execute(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	cmd, args := fields[0], fields[1:]
+
+	switch cmd {
+	case "c", "continue":
+		r.d.Continue()
+	case "p", "pause":
+		r.d.Pause()
+	case "s", "step":
+		r.d.Step()
+	case "n", "next":
+		r.d.StepOver()
+	case "o", "out":
+		return r.d.StepOut()
+	case "b", "break":
+		if len(args) != 1 {
+			return errors.New("usage: break <addr>")
+		}
+		addr, err := ParseNumber(args[0])
+		if err != nil {
+			return err
+		}
+		return r.added(NewPCBreakpoint(addr), nil)
+	case "bo":
+		if len(args) != 1 {
+			return errors.New("usage: bo <pattern>")
+		}
+		return r.added(NewOpcodeBreakpoint(args[0]))
+	case "bc":
+		if len(args) != 3 {
+			return errors.New("usage: bc <reg> <op> <value>")
+		}
+		return r.added(NewConditionBreakpoint(args[0], args[1], args[2]))
+	case "w", "watch":
+		if len(args) != 1 && len(args) != 2 {
+			return errors.New("usage: watch <addr> [r|w|rw]")
+		}
+		addr, err := ParseNumber(args[0])
+		if err != nil {
+			return err
+		}
+		access := ""
+		if len(args) == 2 {
+			access = args[1]
+		}
+		return r.added(NewWatchpoint(addr, access))
+	case "l", "list":
+		for _, b := range r.d.Breakpoints() {
+			fmt.Fprintln(r.out, b)
+		}
+	case "d", "delete":
+		if len(args) != 1 {
+			return errors.New("usage: delete <id>")
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil {
+			return errors.New("'" + args[0] + "' is not the id of a breakpoint")
+		}
+		return r.d.Delete(id)
+	case "r", "regs":
+		fmt.Fprintln(r.out, r.d.Status())
+	case "x":
+		return r.memory(args)
+	case "h", "help":
+		fmt.Fprintln(r.out, help)
+	default:
+		return errors.New("unknown command '" + cmd + "', type help to see the commands")
+	}
+	return nil
+}
+
+//added adds the breakpoint b, unless there was an error creating it
+func (r *REPL) added(b Breakpoint, err error) error {
+	if err != nil {
+		return err
+	}
+	id := r.d.Add(b)
+	fmt.Fprintf(r.out, "#%d %s\n", id, b)
+	return nil
+}
+
+//memory writes a hex dump of the memory, 16 bytes per line
+func (r *REPL) memory(args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("usage: x <addr> [n]")
+	}
+	addr, err := ParseNumber(args[0])
+	if err != nil {
+		return err
+	}
+	n := uint16(0x10)
+	if len(args) == 2 {
+		n, err = ParseNumber(args[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	bytes := r.d.Memory(addr, int(n))
+	for k := 0; k < len(bytes); k += 16 {
+		end := k + 16
+		if end > len(bytes) {
+			end = len(bytes)
+		}
+		fmt.Fprintf(r.out, "%04X:", int(addr)+k)
+		for _, b := range bytes[k:end] {
+			fmt.Fprintf(r.out, " %02X", b)
+		}
+		fmt.Fprintln(r.out)
+	}
+	return nil
+}
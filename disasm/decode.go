@@ -0,0 +1,203 @@
+package disasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+//flow is how an instruction continues the execution of the program
+type flow int
+
+const (
+	next   flow = iota //Continues with the next instruction
+	jump               //Continues at the target
+	call               //Calls the target and then continues with the next instruction
+	skip               //Continues with the next instruction or the one after it
+	stop               //Doesn't continue, or continues at an address that can't be known (RET, EXIT, JP V0)
+)
+
+//Instruction is an instruction decoded from the memory of the chip8
+type Instruction struct {
+	Opcode uint16
+	Long   uint16 //Address loaded by the XO-CHIP F000 NNNN, which is stored after the opcode
+	Size   int    //Number of bytes of the instruction, 2 or 4
+	Name   string
+	Args   []string
+	Target int //Index in Args of the address the instruction refers to, -1 if it doesn't refer to an address
+	flow   flow
+}
+
+//String returns the instruction in Cowgod's syntax, such as "LD V1, #2F" or "JP #2A0"
+func (in Instruction) String() string {
+	if len(in.Args) == 0 {
+		return in.Name
+	}
+	return in.Name + " " + strings.Join(in.Args, ", ")
+}
+
+//Address returns the address the instruction refers to, if it refers to one
+func (in Instruction) Address() (uint16, bool) {
+	if in.Target < 0 {
+		return 0, false
+	}
+	if in.Opcode == 0xF000 {
+		return in.Long, true
+	}
+	return in.Opcode & 0x0FFF, true
+}
+
+//Decode decodes the instruction stored at the beginning of memory.
+//It returns false if memory doesn't start with a valid instruction.
+func Decode(memory []byte) (Instruction, bool) {
+	if len(memory) < 2 {
+		return Instruction{}, false
+	}
+	oc := uint16(memory[0])<<8 | uint16(memory[1])
+	in := Instruction{Opcode: oc, Size: 2, Target: -1}
+
+	nnn := oc & 0x0FFF
+	kk := byte(oc)
+	n := oc & 0x000F
+	vx := fmt.Sprintf("V%X", oc>>8&0xF)
+	vy := fmt.Sprintf("V%X", oc>>4&0xF)
+
+	set := func(name string, f flow, args ...string) (Instruction, bool) {
+		in.Name = name
+		in.flow = f
+		in.Args = args
+		return in, true
+	}
+	address := func(name string, f flow, args ...string) (Instruction, bool) {
+		in.Target = len(args) - 1
+		return set(name, f, args...)
+	}
+
+	switch oc >> 12 {
+	case 0x0:
+		switch {
+		case oc == 0x00E0:
+			return set("CLS", next)
+		case oc == 0x00EE:
+			return set("RET", stop)
+		case oc&0xFFF0 == 0x00C0:
+			return set("SCD", next, nibble(n))
+		case oc&0xFFF0 == 0x00D0:
+			return set("SCU", next, nibble(n))
+		case oc == 0x00FB:
+			return set("SCR", next)
+		case oc == 0x00FC:
+			return set("SCL", next)
+		case oc == 0x00FD:
+			return set("EXIT", stop)
+		case oc == 0x00FE:
+			return set("LOW", next)
+		case oc == 0x00FF:
+			return set("HIGH", next)
+		}
+	case 0x1:
+		return address("JP", jump, addr(nnn))
+	case 0x2:
+		return address("CALL", call, addr(nnn))
+	case 0x3:
+		return set("SE", skip, vx, byte2(kk))
+	case 0x4:
+		return set("SNE", skip, vx, byte2(kk))
+	case 0x5:
+		switch n {
+		case 0x0:
+			return set("SE", skip, vx, vy)
+		case 0x2:
+			return set("LD", next, "[I]", vx+" - "+vy)
+		case 0x3:
+			return set("LD", next, vx+" - "+vy, "[I]")
+		}
+	case 0x6:
+		return set("LD", next, vx, byte2(kk))
+	case 0x7:
+		return set("ADD", next, vx, byte2(kk))
+	case 0x8:
+		names := map[uint16]string{0x0: "LD", 0x1: "OR", 0x2: "AND", 0x3: "XOR", 0x4: "ADD", 0x5: "SUB", 0x6: "SHR", 0x7: "SUBN", 0xE: "SHL"}
+		if name, ok := names[n]; ok {
+			return set(name, next, vx, vy)
+		}
+	case 0x9:
+		switch n {
+		case 0x0:
+			return set("SNE", skip, vx, vy)
+		case 0x1:
+			return set("MUL", next, vx, vy)
+		case 0x2:
+			return set("DIV", next, vx, vy)
+		}
+	case 0xA:
+		return address("LD", next, "I", addr(nnn))
+	case 0xB:
+		return address("JP", stop, "V0", addr(nnn))
+	case 0xC:
+		return set("RND", next, vx, byte2(kk))
+	case 0xD:
+		return set("DRW", next, vx, vy, nibble(n))
+	case 0xE:
+		switch kk {
+		case 0x9E:
+			return set("SKP", skip, vx)
+		case 0xA1:
+			return set("SKNP", skip, vx)
+		}
+	case 0xF:
+		switch {
+		case oc == 0xF000:
+			if len(memory) < 4 {
+				return Instruction{}, false
+			}
+			in.Size = 4
+			in.Long = uint16(memory[2])<<8 | uint16(memory[3])
+			return address("LD", next, "I", "long "+fmt.Sprintf("#%04X", in.Long))
+		case oc == 0xF002:
+			return set("AUDIO", next)
+		case kk == 0x01:
+			return set("PLANE", next, nibble(oc>>8&0xF))
+		}
+		switch kk {
+		case 0x07:
+			return set("LD", next, vx, "DT")
+		case 0x0A:
+			return set("LD", next, vx, "K")
+		case 0x15:
+			return set("LD", next, "DT", vx)
+		case 0x18:
+			return set("LD", next, "ST", vx)
+		case 0x1E:
+			return set("ADD", next, "I", vx)
+		case 0x29:
+			return set("LD", next, "F", vx)
+		case 0x30:
+			return set("LD", next, "HF", vx)
+		case 0x33:
+			return set("LD", next, "B", vx)
+		case 0x3A:
+			return set("PITCH", next, vx)
+		case 0x55:
+			return set("LD", next, "[I]", vx)
+		case 0x65:
+			return set("LD", next, vx, "[I]")
+		case 0x75:
+			return set("LD", next, "R", vx)
+		case 0x85:
+			return set("LD", next, vx, "R")
+		}
+	}
+	return Instruction{}, false
+}
+
+func addr(a uint16) string {
+	return fmt.Sprintf("#%03X", a)
+}
+
+func byte2(b byte) string {
+	return fmt.Sprintf("#%02X", b)
+}
+
+func nibble(n uint16) string {
+	return fmt.Sprintf("#%X", n)
+}
@@ -0,0 +1,206 @@
+package disasm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+//Origin is the address at which the ROMs are loaded and start running
+const Origin = 0x200
+
+const commentColumn = 28 //Column in which the comments with the address and the bytes of every line start
+
+//Program is a ROM separated in code and data.
+//The code is found following the control flow of the ROM from Origin, every byte which isn't reached is data.
+type Program struct {
+	rom    []byte
+	code   map[uint16]Instruction //Instructions by address
+	inCode map[uint16]bool        //Every byte that belongs to an instruction
+	labels map[uint16]string
+}
+
+//Disassemble separates the code and the data of a ROM loaded at Origin
+func Disassemble(rom []byte) *Program {
+	p := &Program{
+		rom:    rom,
+		code:   map[uint16]Instruction{},
+		inCode: map[uint16]bool{},
+		labels: map[uint16]string{},
+	}
+	p.trace(Origin)
+	p.label()
+	return p
+}
+
+//contains reports if addr is inside the ROM
+func (p *Program) contains(addr uint16) bool {
+	return addr >= Origin && int(addr-Origin) < len(p.rom)
+}
+
+//trace follows the control flow of the program from entry, decoding every instruction it reaches.
+//It stops on the instructions that can't be decoded, which are left as data.
+//The target of JP V0, addr is traced as well, although the value of V0 can't be known.
+func (p *Program) trace(entry uint16) {
+	pending := []uint16{entry}
+	for len(pending) > 0 {
+		addr := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for p.contains(addr) {
+			in, ok := Decode(p.rom[addr-Origin:])
+			if !ok || p.overlaps(addr, in.Size) {
+				break
+			}
+			p.code[addr] = in
+			for k := 0; k < in.Size; k++ {
+				p.inCode[addr+uint16(k)] = true
+			}
+
+			following := addr + uint16(in.Size)
+			if target, ok := in.Address(); ok && in.Opcode>>12 != 0xA && in.Opcode != 0xF000 {
+				pending = append(pending, target)
+			}
+			if in.flow == skip {
+				if skipped, ok := p.decodeAt(following); ok {
+					pending = append(pending, following+uint16(skipped.Size))
+				}
+			}
+			if in.flow == jump || in.flow == stop {
+				break
+			}
+			addr = following
+		}
+	}
+}
+
+//overlaps reports if an instruction of size bytes at addr would overlap an instruction already decoded
+func (p *Program) overlaps(addr uint16, size int) bool {
+	for k := 0; k < size; k++ {
+		if p.inCode[addr+uint16(k)] {
+			return true
+		}
+	}
+	return false
+}
+
+//decodeAt decodes the instruction at addr
+func (p *Program) decodeAt(addr uint16) (Instruction, bool) {
+	if !p.contains(addr) {
+		return Instruction{}, false
+	}
+	return Decode(p.rom[addr-Origin:])
+}
+
+//label names every address inside the ROM referred by an instruction: "L" and the address for code, "D" and the address for data.
+//The addresses in the middle of an instruction are not labelled.
+func (p *Program) label() {
+	p.labels[Origin] = labelName("L", Origin)
+	for _, in := range p.code {
+		target, ok := in.Address()
+		if !ok || !p.contains(target) {
+			continue
+		}
+		if _, isCode := p.code[target]; isCode {
+			p.labels[target] = labelName("L", target)
+		} else if !p.inCode[target] {
+			p.labels[target] = labelName("D", target)
+		}
+	}
+}
+
+func labelName(prefix string, addr uint16) string {
+	return fmt.Sprintf("%s%03X", prefix, addr)
+}
+
+//Labels returns the labels of the program by address
+func (p *Program) Labels() map[uint16]string {
+	labels := map[uint16]string{}
+	for addr, name := range p.labels {
+		labels[addr] = name
+	}
+	return labels
+}
+
+//Instructions returns the addresses of the instructions of the program, in order
+func (p *Program) Instructions() []uint16 {
+	addrs := make([]uint16, 0, len(p.code))
+	for addr := range p.code {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+//text returns the instruction with its address replaced by a label, if it has one
+func (p *Program) text(in Instruction) string {
+	target, ok := in.Address()
+	if !ok {
+		return in.String()
+	}
+	if name, ok := p.labels[target]; ok {
+		if in.Opcode == 0xF000 {
+			name = "long " + name
+		}
+		in.Args = append([]string{}, in.Args...)
+		in.Args[in.Target] = name
+	}
+	return in.String()
+}
+
+//WriteTo writes the program in assembly to w. Every instruction is written in its own line,
+//followed by a comment with its address and its bytes. Every byte of data is written in its own line with DB,
+//followed by a comment with its address and its bits drawn as a row of a sprite.
+func (p *Program) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var written int64
+	line := func(text, comment string) {
+		if comment != "" {
+			text = "    " + text
+			text += strings.Repeat(" ", max(1, commentColumn-len(text))) + "; " + comment
+		}
+		n, _ := bw.WriteString(text + "\n")
+		written += int64(n)
+	}
+
+	for k := 0; k < len(p.rom); {
+		addr := uint16(Origin + k)
+		if name, ok := p.labels[addr]; ok {
+			if k > 0 {
+				line("", "")
+			}
+			line(name+":", "")
+		}
+		if in, ok := p.code[addr]; ok {
+			line(p.text(in), fmt.Sprintf("%03X  %X", addr, p.rom[k:k+in.Size]))
+			k += in.Size
+			continue
+		}
+		b := p.rom[k]
+		line(fmt.Sprintf("DB #%02X", b), fmt.Sprintf("%03X  %s", addr, sprite(b)))
+		k++
+	}
+	return written, bw.Flush()
+}
+
+//sprite draws the bits of a byte of a sprite, # for the pixels that are on and . for the ones that are off
+func sprite(b byte) string {
+	var sb strings.Builder
+	for bit := 7; bit >= 0; bit-- {
+		if b>>uint(bit)&1 == 1 {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+	return sb.String()
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,122 @@
+package disasm
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		memory   []byte
+		expected string
+	}{
+		{[]byte{0x00, 0xE0}, "CLS"},
+		{[]byte{0x00, 0xEE}, "RET"},
+		{[]byte{0x12, 0xA0}, "JP #2A0"},
+		{[]byte{0x2F, 0x00}, "CALL #F00"},
+		{[]byte{0x3A, 0x02}, "SE VA, #02"},
+		{[]byte{0x51, 0x20}, "SE V1, V2"},
+		{[]byte{0x8A, 0xB6}, "SHR VA, VB"},
+		{[]byte{0x8A, 0xBE}, "SHL VA, VB"},
+		{[]byte{0x91, 0x21}, "MUL V1, V2"},
+		{[]byte{0x91, 0x22}, "DIV V1, V2"},
+		{[]byte{0xA2, 0xEA}, "LD I, #2EA"},
+		{[]byte{0xB3, 0x00}, "JP V0, #300"},
+		{[]byte{0xD0, 0x15}, "DRW V0, V1, #5"},
+		{[]byte{0xE3, 0xA1}, "SKNP V3"},
+		{[]byte{0xF4, 0x0A}, "LD V4, K"},
+		{[]byte{0xF4, 0x65}, "LD V4, [I]"},
+		{[]byte{0x00, 0xC4}, "SCD #4"},
+		{[]byte{0xF2, 0x30}, "LD HF, V2"},
+		{[]byte{0x52, 0x42}, "LD [I], V2 - V4"},
+		{[]byte{0xF0, 0x00, 0x12, 0x34}, "LD I, long #1234"},
+		{[]byte{0xF3, 0x01}, "PLANE #3"},
+	}
+	for _, test := range tests {
+		in, ok := Decode(test.memory)
+		assert.True(t, ok, test.expected)
+		assert.Equal(t, test.expected, in.String(), "")
+		assert.Equal(t, len(test.memory), in.Size, test.expected)
+	}
+
+	for _, invalid := range [][]byte{{0x51, 0x21}, {0x8A, 0xB8}, {0xE0, 0x00}, {0xF0, 0x00, 0x12}, {0x12}} {
+		_, ok := Decode(invalid)
+		assert.False(t, ok, "% X is not an instruction", invalid)
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	rom := []byte{
+		0xA2, 0x0A, //200: LD I, D20A
+		0x3A, 0x00, //202: SE VA, #00
+		0x22, 0x08, //204: CALL L208
+		0x12, 0x06, //206: JP L206
+		0x00, 0xEE, //208: RET
+		0x3C, //20A: sprite
+		0x42, //20B: sprite
+	}
+	var out bytes.Buffer
+	_, err := Disassemble(rom).WriteTo(&out)
+	assert.NoError(t, err, "")
+	assert.Equal(t, `L200:
+    LD I, D20A              ; 200  A20A
+    SE VA, #00              ; 202  3A00
+    CALL L208               ; 204  2208
+
+L206:
+    JP L206                 ; 206  1206
+
+L208:
+    RET                     ; 208  00EE
+
+D20A:
+    DB #3C                  ; 20A  ..####..
+    DB #42                  ; 20B  .#....#.
+`, out.String(), "")
+}
+
+//TestDisassemble_Assets checks that every byte of the ROMs is written once, as part of an instruction or as data
+func TestDisassemble_Assets(t *testing.T) {
+	roms, _ := filepath.Glob("../assets/*.ch8")
+	assert.NotEmpty(t, roms, "")
+	for _, rom := range roms {
+		data, err := ioutil.ReadFile(rom)
+		assert.NoError(t, err, "")
+
+		var out bytes.Buffer
+		_, err = Disassemble(data).WriteTo(&out)
+		assert.NoError(t, err, rom)
+
+		size := 0
+		for _, line := range strings.Split(out.String(), "\n") {
+			if k := strings.Index(line, "; "); k >= 0 {
+				fields := strings.Fields(line[k+2:])
+				if strings.HasPrefix(line, "    DB ") {
+					size++
+				} else {
+					size += len(fields[1]) / 2
+				}
+			}
+		}
+		assert.Equal(t, len(data), size, rom)
+	}
+}
+
+func TestProgram_Platform(t *testing.T) {
+	tests := []struct {
+		rom      []byte
+		expected string
+	}{
+		{[]byte{0x60, 0x01, 0xD0, 0x15, 0x12, 0x04}, PlatformCHIP8},
+		{[]byte{0x00, 0xFF, 0xD0, 0x10, 0x12, 0x04}, PlatformSCHIP},
+		{[]byte{0x00, 0xFF, 0xF2, 0x01, 0x12, 0x04}, PlatformXOCHIP},
+		{[]byte{0x12, 0x04, 0x00, 0xFF, 0x12, 0x04}, PlatformCHIP8}, //00FF is never reached
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Disassemble(test.rom).Platform(), "% X", test.rom)
+	}
+}
@@ -0,0 +1,30 @@
+package disasm
+
+//Platforms of the programs, each of them runs the programs of the previous ones
+const (
+	PlatformCHIP8  = "CHIP-8"
+	PlatformSCHIP  = "SUPER-CHIP"
+	PlatformXOCHIP = "XO-CHIP"
+)
+
+var platforms = []string{PlatformCHIP8, PlatformSCHIP, PlatformXOCHIP}
+
+//Platform returns the platform a program needs, given by the instructions found in its code
+func (p *Program) Platform() string {
+	platform := 0
+	for _, in := range p.code {
+		platform = max(platform, platformOf(in.Opcode))
+	}
+	return platforms[platform]
+}
+
+//platformOf returns the index in platforms of the first platform which has the instruction of the opcode
+func platformOf(oc uint16) int {
+	switch {
+	case oc&0xFFF0 == 0x00D0, oc&0xF00E == 0x5002, oc == 0xF000, oc&0xF0FF == 0xF001, oc == 0xF002, oc&0xF0FF == 0xF03A:
+		return 2
+	case oc&0xFFF0 == 0x00C0, oc >= 0x00FB && oc <= 0x00FF, oc&0xF00F == 0xD000, oc&0xF0FF == 0xF030, oc&0xF0FF == 0xF075, oc&0xF0FF == 0xF085:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,222 @@
+package golden
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/headless"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	Magic   = "chip8-golden" //First word of the golden traces
+	Version = 3              //Version of the format of the golden traces, 2 added the random source and 3 its data
+)
+
+//ErrStateVersion is returned when a golden trace was recorded with another version of chip8.Snapshot, so the hashes of its frames can't be compared
+var ErrStateVersion = errors.New("the trace was recorded with another version of the state of the chip8, it must be recorded again")
+
+//Write writes the trace to w as text compressed with gzip. The text has the header, a line per key event,
+//and then a line per cycle and a line per frame, in the order in which they happened:
+//
+//	chip8-golden 3
+//	rom <SHA-1 of the ROM>
+//	profile vip
+//	ipf 8
+//	cycles 3000
+//	random prng 42
+//	data A3 07 FF
+//	state <chip8.StateVersion>
+//	key 1500 down 1
+//	c <PC> <I> <SP> <DT> <ST> <V0 to VF> [<address>=<byte> ...]
+//	f <hash of the state> <hash of the screen>
+//
+//The line of the data has the bytes of the random source read from a file, the sequence or the page of the VIP interpreter, and it's only written with those sources.
+//The numbers of the lines of the cycles and the frames are hexadecimal, the ones of the header and of the key events are decimal.
+func (t *Trace) Write(w io.Writer) error {
+	z := gzip.NewWriter(w)
+	b := bufio.NewWriter(z)
+	fmt.Fprintf(b, "%s %d\nrom %s\nprofile %s\nipf %d\ncycles %d\nrandom %s %d\n", Magic, Version, t.ROM, t.Profile, t.IPF, t.Cycles, t.Random, t.Seed)
+	if len(t.Data) > 0 {
+		fmt.Fprintf(b, "data % X\n", t.Data)
+	}
+	fmt.Fprintf(b, "state %d\n", chip8.StateVersion)
+	for _, e := range t.Events {
+		action := "up"
+		if e.Down {
+			action = "down"
+		}
+		fmt.Fprintf(b, "key %d %s %X\n", e.Cycle, action, e.Key)
+	}
+	frames := t.Frames
+	for k, s := range t.Steps {
+		r := s.Registers
+		fmt.Fprintf(b, "c %04X %04X %02X %02X %02X %X", r.PC, r.I, r.SP, r.DelayTimer, r.SoundTimer, r.V[:])
+		for _, w := range s.Writes {
+			fmt.Fprintf(b, " %04X=%02X", w.Addr, w.Value)
+		}
+		b.WriteByte('\n')
+		if (k+1)%t.IPF == 0 && len(frames) > 0 {
+			fmt.Fprintf(b, "f %016X %016X\n", frames[0].State, frames[0].Screen)
+			frames = frames[1:]
+		}
+	}
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+//Create writes the trace to the file filename
+func (t *Trace) Create(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = t.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//Read reads a trace written by Write. If it was recorded with another version of chip8.Snapshot, it returns ErrStateVersion.
+func Read(r io.Reader) (*Trace, error) {
+	z, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.New("not a golden trace")
+	}
+	defer z.Close()
+	t := &Trace{}
+	var keys strings.Builder
+	scanner := bufio.NewScanner(z)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if line == 1 {
+			if fields[0] != Magic || len(fields) != 2 {
+				return nil, errors.New("not a golden trace")
+			}
+			if fields[1] != strconv.Itoa(Version) {
+				return nil, fmt.Errorf("the golden trace has the version %s of the format, which isn't supported: it must be recorded again with version %d", fields[1], Version)
+			}
+			continue
+		}
+		if err = t.parse(fields, &keys); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if t.Events, err = headless.ParseKeyEvents(strings.NewReader(keys.String())); err != nil {
+		return nil, err
+	}
+	if t.IPF <= 0 {
+		return nil, errors.New("the trace doesn't have the instructions per frame")
+	}
+	return t, nil
+}
+
+//Open reads the trace of the file filename
+func Open(filename string) (*Trace, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+//parse adds a line of a trace to it, the lines of the key events are added to keys to be parsed by headless.ParseKeyEvents
+func (t *Trace) parse(fields []string, keys *strings.Builder) error {
+	var err error
+	switch fields[0] {
+	case "rom":
+		t.ROM = strings.Join(fields[1:], "")
+	case "profile":
+		t.Profile = strings.Join(fields[1:], "")
+	case "ipf":
+		t.IPF, err = strconv.Atoi(strings.Join(fields[1:], ""))
+	case "cycles":
+		t.Cycles, err = strconv.ParseUint(strings.Join(fields[1:], ""), 10, 64)
+	case "random":
+		if len(fields) != 3 {
+			return errors.New("expected 'random <source> <seed>'")
+		}
+		t.Random = fields[1]
+		t.Seed, err = strconv.ParseInt(fields[2], 10, 64)
+	case "data":
+		t.Data, err = chip8.ParseSequence(strings.NewReader(strings.Join(fields[1:], " ")))
+	case "state":
+		if strings.Join(fields[1:], "") != strconv.Itoa(chip8.StateVersion) {
+			return ErrStateVersion
+		}
+	case "key":
+		keys.WriteString(strings.Join(fields[1:], " ") + "\n")
+	case "c":
+		var s Step
+		s, err = parseStep(fields[1:])
+		t.Steps = append(t.Steps, s)
+	case "f":
+		var f Frame
+		if len(fields) != 3 {
+			return errors.New("expected 'f <state> <screen>'")
+		}
+		if f.State, err = strconv.ParseUint(fields[1], 16, 64); err == nil {
+			f.Screen, err = strconv.ParseUint(fields[2], 16, 64)
+		}
+		t.Frames = append(t.Frames, f)
+	default:
+		return fmt.Errorf("unknown line '%s'", fields[0])
+	}
+	return err
+}
+
+//parseStep parses the fields of the line of a cycle
+func parseStep(fields []string) (Step, error) {
+	var s Step
+	if len(fields) < 6 || len(fields[5]) != 2*chip8.NumberOfRegisters {
+		return s, errors.New("expected 'c <PC> <I> <SP> <DT> <ST> <V0 to VF> [<address>=<byte> ...]'")
+	}
+	var values [5]uint64
+	for k, bits := range []int{16, 16, 8, 8, 8} {
+		v, err := strconv.ParseUint(fields[k], 16, bits)
+		if err != nil {
+			return s, err
+		}
+		values[k] = v
+	}
+	r := &s.Registers
+	r.PC, r.I, r.SP, r.DelayTimer, r.SoundTimer = uint16(values[0]), uint16(values[1]), byte(values[2]), byte(values[3]), byte(values[4])
+	for k := range r.V {
+		v, err := strconv.ParseUint(fields[5][2*k:2*k+2], 16, 8)
+		if err != nil {
+			return s, err
+		}
+		r.V[k] = byte(v)
+	}
+	for _, field := range fields[6:] {
+		parts := strings.Split(field, "=")
+		if len(parts) != 2 {
+			return s, fmt.Errorf("'%s' is not a byte written to memory", field)
+		}
+		addr, err := strconv.ParseUint(parts[0], 16, 16)
+		if err != nil {
+			return s, err
+		}
+		value, err := strconv.ParseUint(parts[1], 16, 8)
+		if err != nil {
+			return s, err
+		}
+		s.Writes = append(s.Writes, Write{Addr: uint16(addr), Value: byte(value)})
+	}
+	return s, nil
+}
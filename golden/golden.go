@@ -0,0 +1,254 @@
+package golden
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/headless"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+//Header describes the run recorded in a golden trace: the ROM, the settings of the chip8 and the keys pressed
+type Header struct {
+	ROM     string              //SHA-1 of the ROM in hexadecimal, as romdb.Hash returns it
+	Profile string              //Quirks profile
+	IPF     int                 //Instructions executed per frame
+	Cycles  uint64              //Cycles executed
+	Random  string              //Name of the random source, as chip8.ParseRandom takes it
+	Seed    int64               //Seed of the random source
+	Data    []byte              //Bytes of the random source read from a file, as chip8.RandomData returns them, so the trace doesn't need the file
+	Events  []headless.KeyEvent //Keys pressed and released, the inputs of the run
+}
+
+//RandomOption returns the option of the random source of the header, with which the runner of Verify must be created
+func (h Header) RandomOption() (chip8.Option, error) {
+	return chip8.RandomOption(h.Random, h.Seed, h.Data)
+}
+
+//Trace is a golden trace: the state of the chip8 after every cycle of a run and at the end of every frame,
+//which a later run of the same ROM with the same inputs must reproduce
+type Trace struct {
+	Header
+	Steps  []Step  //A step per cycle
+	Frames []Frame //A frame per IPF cycles
+}
+
+//Step is the state of the chip8 after a cycle: its registers and the bytes of memory written by the instruction.
+//The stack isn't in the registers, its changes are in the hashes of the frames.
+type Step struct {
+	Registers chip8.Registers
+	Writes    []Write
+}
+
+//Write is a byte written to memory
+type Write struct {
+	Addr  uint16
+	Value byte
+}
+
+//Frame is the state of the chip8 at the end of a frame, before its timers are ticked, as FNV-1a hashes
+type Frame struct {
+	State  uint64 //Hash of the whole state of the chip8, its chip8.Snapshot
+	Screen uint64 //Hash of the pixels of the frame buffer and its resolution
+}
+
+//Divergence is the first difference between a run of the chip8 and its golden trace
+type Divergence struct {
+	Cycle  uint64   //Cycle whose state differs from the trace, counted from 1
+	PC     uint16   //Address of the instruction executed by the cycle
+	Opcode uint16   //Opcode of the instruction executed by the cycle
+	Diffs  []string //Differences, such as "V3: expected 05, got 06"
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("the run diverges from the trace at the cycle %d (PC #%03X, opcode %04X):\n\t%s", d.Cycle, d.PC, d.Opcode, strings.Join(d.Diffs, "\n\t"))
+}
+
+//Record runs the chip8 of the runner for the cycles of the header, or until the program exits, and records every cycle and every frame.
+//The runner must have been created with the events and the random source of the header, and must not have been run.
+//Its IPF is set to the one of the header, and the seed of the header is set to the one of its chip8 (and the random source to prng if it's empty).
+//If the random source reads its bytes from a file, a chip8.Sequence or a chip8.VIPRandom, they're recorded in the header.
+//If the program makes the chip8 fail, Record returns the *chip8.Fault.
+func Record(r *headless.Runner, h Header) (*Trace, error) {
+	t := &Trace{Header: h}
+	t.Seed = r.Chip8().RandomSeed()
+	if t.Random == "" {
+		t.Random = "prng"
+	}
+	t.Data = chip8.RandomData(r.Chip8().RandomSource())
+	r.IPF = h.IPF
+	p := newProbe(r.Chip8())
+	r.Cycle = func() {
+		t.Steps = append(t.Steps, p.step())
+	}
+	r.Frame = func() {
+		t.Frames = append(t.Frames, frame(r.Chip8()))
+	}
+	err := r.Run(h.Cycles)
+	t.Cycles = r.Cycles()
+	return t, err
+}
+
+//Verify runs the chip8 of the runner like the run of the trace, and compares its state with the trace after every cycle and at the end of every frame.
+//The runner must have been created with the events, the quirks profile and the random source of the trace (Header.RandomOption), and must not have been run.
+//Its IPF is set to the one of the trace, and its random source is started with the seed of the trace.
+//At the first cycle whose state differs, it stops and returns a *Divergence with the registers and the bytes of memory that differ.
+//If the program makes the chip8 fail, Verify returns the *chip8.Fault.
+func Verify(r *headless.Runner, t *Trace) error {
+	c8 := r.Chip8()
+	r.IPF = t.IPF
+	c8.SeedRandom(t.Seed)
+	p := newProbe(c8)
+	memory := make([]byte, chip8.AddressSpace) //Memory of the run of the trace
+	for addr := range memory {
+		memory[addr] = c8.ReadMemory(uint16(addr))
+	}
+	var divergence *Divergence
+	diverge := func(diffs []string) {
+		divergence = &Divergence{Cycle: r.Cycles(), PC: p.pc, Opcode: p.opcode, Diffs: diffs}
+		c8.Close()
+	}
+
+	steps, frames := 0, 0
+	r.Cycle = func() {
+		got := p.step()
+		if steps >= len(t.Steps) {
+			diverge([]string{"the trace has ended"})
+			return
+		}
+		want := t.Steps[steps]
+		steps++
+		diffs := diffRegisters(want.Registers, got.Registers)
+		for _, w := range want.Writes {
+			memory[w.Addr] = w.Value
+		}
+		diffs = append(diffs, diffMemory(memory, c8, append(want.Writes, got.Writes...))...)
+		if len(diffs) > 0 {
+			diverge(diffs)
+		}
+	}
+	r.Frame = func() {
+		if frames >= len(t.Frames) {
+			diverge([]string{"the trace has ended"})
+			return
+		}
+		want, got := t.Frames[frames], frame(c8)
+		frames++
+		if want.Screen != got.Screen {
+			diverge([]string{"the screen differs at the end of the frame"})
+		} else if want.State != got.State {
+			diverge([]string{"the state differs at the end of the frame: the stack, the timers or the state of the display"})
+		}
+	}
+
+	if err := r.Run(t.Cycles); err != nil {
+		return err
+	}
+	if divergence != nil {
+		return divergence
+	}
+	if steps < len(t.Steps) {
+		return &Divergence{Cycle: r.Cycles(), PC: p.pc, Opcode: p.opcode, Diffs: []string{fmt.Sprintf("the program exits, in the trace it runs until the cycle %d", len(t.Steps))}}
+	}
+	return nil
+}
+
+//probe takes the state of a chip8 after every cycle, with the bytes written to memory by the instruction
+type probe struct {
+	c8         *chip8.Chip8
+	written    []uint16 //Addresses written by the current instruction
+	pc, opcode uint16   //Instruction executed by the last cycle
+	nextPC     uint16   //Address of the instruction of the next cycle
+	nextOpcode uint16
+}
+
+//newProbe instantiates a probe which is called on every memory access of the chip8
+func newProbe(c8 *chip8.Chip8) *probe {
+	p := &probe{c8: c8, nextPC: c8.Registers().PC, nextOpcode: c8.PeekOpcode()}
+	c8.SetMemoryHook(func(addr uint16, n int, write bool) {
+		if !write {
+			return
+		}
+		for k := 0; k < n; k++ {
+			p.written = append(p.written, addr+uint16(k))
+		}
+	})
+	return p
+}
+
+//step returns the state of the chip8 after a cycle, and prepares the probe for the next one
+func (p *probe) step() Step {
+	s := Step{Registers: p.c8.Registers()}
+	s.Registers.Stack = [chip8.StackLevels]uint16{}
+	for _, addr := range p.written {
+		s.Writes = append(s.Writes, Write{Addr: addr, Value: p.c8.ReadMemory(addr)})
+	}
+	p.written = p.written[:0]
+	p.pc, p.opcode = p.nextPC, p.nextOpcode
+	p.nextPC, p.nextOpcode = s.Registers.PC, p.c8.PeekOpcode()
+	return s
+}
+
+//frame returns the hashes of the state of the chip8
+func frame(c8 *chip8.Chip8) Frame {
+	s := c8.Snapshot()
+	state := fnv.New64a()
+	state.Write(s.Memory[:])
+	_ = binary.Write(state, binary.BigEndian, &s.Machine)
+
+	fb := c8.GetFrameBuffer()
+	screen := fnv.New64a()
+	screen.Write(fb.Pixels[:fb.Width()*fb.Height()])
+	if fb.HighRes {
+		screen.Write([]byte{1})
+	}
+	return Frame{State: state.Sum64(), Screen: screen.Sum64()}
+}
+
+//diffMemory returns the bytes of memory written by the last cycle whose values differ from the ones of the memory of the trace
+func diffMemory(memory []byte, c8 *chip8.Chip8, writes []Write) []string {
+	var addrs []int
+	seen := map[uint16]bool{}
+	for _, w := range writes {
+		if !seen[w.Addr] {
+			seen[w.Addr] = true
+			addrs = append(addrs, int(w.Addr))
+		}
+	}
+	sort.Ints(addrs)
+	var diffs []string
+	for _, addr := range addrs {
+		if got := c8.ReadMemory(uint16(addr)); got != memory[addr] {
+			diffs = append(diffs, fmt.Sprintf("memory #%04X: expected %02X, got %02X", addr, memory[addr], got))
+		}
+	}
+	return diffs
+}
+
+//diffRegisters returns the registers whose values differ
+func diffRegisters(want, got chip8.Registers) []string {
+	var diffs []string
+	for k := range want.V {
+		if want.V[k] != got.V[k] {
+			diffs = append(diffs, fmt.Sprintf("V%X: expected %02X, got %02X", k, want.V[k], got.V[k]))
+		}
+	}
+	values := []struct {
+		name      string
+		want, got uint16
+	}{
+		{"PC", want.PC, got.PC},
+		{"I", want.I, got.I},
+		{"SP", uint16(want.SP), uint16(got.SP)},
+		{"DT", uint16(want.DelayTimer), uint16(got.DelayTimer)},
+		{"ST", uint16(want.SoundTimer), uint16(got.SoundTimer)},
+	}
+	for _, v := range values {
+		if v.want != v.got {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %03X, got %03X", v.name, v.want, v.got))
+		}
+	}
+	return diffs
+}
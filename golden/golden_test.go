@@ -0,0 +1,209 @@
+package golden
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"github.com/NoetherianRing/Chip-8/asm"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/headless"
+	"github.com/NoetherianRing/Chip-8/romdb"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//update rewrites the golden traces of testdata with the runs of the chip8: go test ./golden -update
+var update = flag.Bool("update", false, "rewrite the golden traces")
+
+//keysSource waits for a key, draws its digit and writes its BCD to memory, forever
+const keysSource = `
+loop:   LD V0, K
+        LD F, V0
+        CLS
+        DRW V1, V1, 5
+        LD I, #300
+        LD B, V0
+        JP loop
+`
+
+//keysEvents press the keys 7 and C
+var keysEvents = []headless.KeyEvent{
+	{Cycle: 10, Key: 0x7, Down: true},
+	{Cycle: 40, Key: 0x7, Down: false},
+	{Cycle: 100, Key: 0xC, Down: true},
+	{Cycle: 130, Key: 0xC, Down: false},
+}
+
+//newRunner instantiates a runner with the vip profile and the events, and loads the ROM file into its chip8
+func newRunner(t *testing.T, filename string, events []headless.KeyEvent) *headless.Runner {
+	quirks, err := chip8.QuirksProfile("vip")
+	assert.NoError(t, err, "")
+	r, err := headless.NewRunner(quirks, events)
+	assert.NoError(t, err, "")
+	assert.NoError(t, r.Chip8().LoadROM(filename), "")
+	return r
+}
+
+//recordKeys assembles the ROM of keysSource into a temporary file, and records a trace of it pressing the keys of keysEvents
+func recordKeys(t *testing.T) (string, *Trace) {
+	program, err := asm.AssembleSource("keys.asm", keysSource)
+	assert.NoError(t, err, "")
+	filename := filepath.Join(t.TempDir(), "keys.ch8")
+	assert.NoError(t, ioutil.WriteFile(filename, program.ROM, 0644), "")
+
+	trace, err := Record(newRunner(t, filename, keysEvents), Header{ROM: romdb.Hash(program.ROM), Profile: "vip", IPF: 10, Cycles: 200, Events: keysEvents})
+	assert.NoError(t, err, "")
+	return filename, trace
+}
+
+//goldenROMs are the ROMs of the assets whose golden traces are in testdata, with the keys pressed in them
+var goldenROMs = []struct {
+	name   string
+	events []headless.KeyEvent
+}{
+	{"IBM_Logo", nil},
+	{"chip8_logo", nil},
+	{"PONG", []headless.KeyEvent{
+		{Cycle: 300, Key: 0x1, Down: true},
+		{Cycle: 800, Key: 0x1, Down: false},
+		{Cycle: 1500, Key: 0x4, Down: true},
+		{Cycle: 2200, Key: 0x4, Down: false},
+	}},
+}
+
+func TestVerify(t *testing.T) {
+	for _, rom := range goldenROMs {
+		filename, romFile := filepath.Join("testdata", rom.name+".golden"), filepath.Join("../assets", rom.name+".ch8")
+		program, err := ioutil.ReadFile(romFile)
+		assert.NoError(t, err, "")
+		if *update {
+			r := newRunner(t, romFile, rom.events)
+			trace, err := Record(r, Header{ROM: romdb.Hash(program), Profile: "vip", IPF: r.IPF, Cycles: 3000, Events: rom.events})
+			assert.NoError(t, err, "")
+			assert.NoError(t, trace.Create(filename), "")
+		}
+		trace, err := Open(filename)
+		if !assert.NoError(t, err, "the golden trace is missing, write it with -update") {
+			continue
+		}
+		assert.Equal(t, romdb.Hash(program), trace.ROM, rom.name)
+		assert.Equal(t, 3000, len(trace.Steps), rom.name)
+		assert.Equal(t, 3000/trace.IPF, len(trace.Frames), rom.name)
+		assert.Equal(t, rom.events, trace.Events, rom.name)
+		assert.NoError(t, Verify(newRunner(t, romFile, trace.Events), trace), rom.name)
+	}
+}
+
+func TestVerify_Divergence(t *testing.T) {
+	filename, trace := recordKeys(t)
+	assert.Equal(t, 200, len(trace.Steps), "")
+	assert.Equal(t, 20, len(trace.Frames), "")
+	verify := func(events []headless.KeyEvent) *Divergence {
+		err := Verify(newRunner(t, filename, events), trace)
+		var d *Divergence
+		if err != nil {
+			assert.True(t, errors.As(err, &d), "")
+		}
+		return d
+	}
+	assert.Nil(t, verify(keysEvents), "")
+
+	//The key C is released one cycle later: FX0A keeps waiting for it for an extra cycle
+	late := append([]headless.KeyEvent{}, keysEvents...)
+	late[2].Cycle, late[3].Cycle = 101, 131
+	d := verify(late)
+	if assert.NotNil(t, d, "") {
+		assert.Equal(t, uint64(131), d.Cycle, "")
+		assert.Equal(t, uint16(0x200), d.PC, "")
+		assert.Equal(t, uint16(0xF00A), d.Opcode, "")
+		assert.Equal(t, []string{"V0: expected 0C, got 07", "PC: expected 202, got 200"}, d.Diffs, "")
+		assert.Contains(t, d.Error(), "at the cycle 131 (PC #200, opcode F00A)", "")
+	}
+
+	//The key 8 is held down instead of the key C: the keypad is in the state of the chip8 at the end of the frame
+	wrong := append([]headless.KeyEvent{}, keysEvents...)
+	wrong[2].Key, wrong[3].Key = 0x8, 0x8
+	d = verify(wrong)
+	if assert.NotNil(t, d, "") {
+		assert.Equal(t, uint64(110), d.Cycle, "")
+		assert.Equal(t, []string{"the state differs at the end of the frame: the stack, the timers or the state of the display"}, d.Diffs, "")
+	}
+
+	//The BCD of C is written by the cycle 142, after drawing the digit and waiting for the vertical blank
+	writes := trace.Steps[141].Writes
+	assert.Equal(t, []Write{{0x300, 0}, {0x301, 1}, {0x302, 2}}, writes, "")
+	writes[2].Value++
+	d = verify(keysEvents)
+	if assert.NotNil(t, d, "") {
+		assert.Equal(t, uint64(142), d.Cycle, "")
+		assert.Equal(t, uint16(0xF033), d.Opcode, "")
+		assert.Equal(t, []string{"memory #0302: expected 03, got 02"}, d.Diffs, "")
+	}
+	writes[2].Value--
+
+	trace.Frames[3].Screen++
+	d = verify(keysEvents)
+	if assert.NotNil(t, d, "") {
+		assert.Equal(t, uint64(40), d.Cycle, "")
+		assert.Equal(t, []string{"the screen differs at the end of the frame"}, d.Diffs, "")
+	}
+}
+
+func TestTrace_WriteRead(t *testing.T) {
+	_, trace := recordKeys(t)
+	var b bytes.Buffer
+	assert.NoError(t, trace.Write(&b), "")
+	read, err := Read(bytes.NewReader(b.Bytes()))
+	assert.NoError(t, err, "")
+	assert.Equal(t, trace, read, "")
+
+	_, err = Read(strings.NewReader("c 0200"))
+	assert.Error(t, err, "")
+
+	var old bytes.Buffer
+	z := gzip.NewWriter(&old)
+	_, _ = z.Write([]byte("chip8-golden 1\nrom \nprofile vip\nipf 8\ncycles 3000\nstate 1\n"))
+	assert.NoError(t, z.Close(), "")
+	_, err = Read(&old)
+	if assert.Error(t, err, "") {
+		assert.Contains(t, err.Error(), "version 1", "the traces without the random source must be recorded again")
+	}
+}
+
+//TestRecord_Sequence records a program which draws random numbers from a sequence, and verifies it with the sequence of the trace
+func TestRecord_Sequence(t *testing.T) {
+	program, err := asm.AssembleSource("random.asm", `
+loop:   RND V0, #FF
+        JP loop
+`)
+	assert.NoError(t, err, "")
+	filename := filepath.Join(t.TempDir(), "random.ch8")
+	assert.NoError(t, ioutil.WriteFile(filename, program.ROM, 0644), "")
+	runner := func(option chip8.Option) *headless.Runner {
+		r, err := headless.NewRunner(chip8.Quirks{}, nil, option)
+		assert.NoError(t, err, "")
+		assert.NoError(t, r.Chip8().LoadROM(filename), "")
+		return r
+	}
+
+	trace, err := Record(runner(chip8.WithRandom(chip8.Sequence{0x11, 0x22, 0x33}, 1)), Header{Random: "sequence", IPF: 10, Cycles: 20})
+	assert.NoError(t, err, "")
+	assert.Equal(t, []byte{0x11, 0x22, 0x33}, trace.Data, "")
+	assert.Equal(t, byte(0x22), trace.Steps[0].Registers.V[0], "")
+
+	var b bytes.Buffer
+	assert.NoError(t, trace.Write(&b), "")
+	read, err := Read(&b)
+	assert.NoError(t, err, "")
+	assert.Equal(t, trace, read, "")
+
+	option, err := read.RandomOption()
+	assert.NoError(t, err, "")
+	assert.NoError(t, Verify(runner(option), read), "the trace has the numbers of the sequence")
+	var d *Divergence
+	assert.True(t, errors.As(Verify(runner(chip8.WithRandom(chip8.PRNG{}, 1)), read), &d), "other numbers")
+}
@@ -0,0 +1,51 @@
+package headless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//KeyEvent is a key of the keypad pressed (Down) or released at a cycle of the runner
+type KeyEvent struct {
+	Cycle uint64
+	Key   byte
+	Down  bool
+}
+
+//ParseKeyEvents reads a script of key events, one per line with the format "cycle down|up key",
+//where cycle is decimal and key is the hexadecimal key of the keypad. The lines starting with ; are comments.
+//	; press 5 at the cycle 120 and release it 30 cycles later
+//	120 down 5
+//	150 up 5
+func ParseKeyEvents(r io.Reader) ([]KeyEvent, error) {
+	var events []KeyEvent
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, ";") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 3 || (fields[1] != "down" && fields[1] != "up") {
+			return nil, fmt.Errorf("line %d: expected 'cycle down|up key', found '%s'", line, text)
+		}
+		cycle, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: '%s' is not a cycle", line, fields[0])
+		}
+		key, err := strconv.ParseUint(fields[2], 16, 4)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: '%s' is not a key of the keypad", line, fields[2])
+		}
+		events = append(events, KeyEvent{Cycle: cycle, Key: byte(key), Down: fields[1] == "down"})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Cycle < events[j].Cycle })
+	return events, nil
+}
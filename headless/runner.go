@@ -0,0 +1,132 @@
+package headless
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"image/png"
+	"io"
+)
+
+//ErrIPF is returned by Run and RunUntilStable when the runner doesn't execute any instruction per frame
+var ErrIPF = errors.New("the instructions per frame must be at least 1")
+
+//Runner executes a chip8 without a window, cycling it as fast as possible and pressing the keys of a script.
+//It only uses the goroutine which calls Run, so the runs are reproducible.
+type Runner struct {
+	c8      *chip8.Chip8
+	keypad  *chip8.KeypadState
+	events  []KeyEvent
+	cycle   uint64
+	IPF     int             //Instructions per frame, the cycles between two calls to chip8.TickTimers
+	Palette monitor.Palette //Colours of the PNG images
+	Frame   func()          //Called at the end of every frame before ticking the timers, if it isn't nil
+	Cycle   func()          //Called after every cycle, before the end of the frame, if it isn't nil
+}
+
+//NewRunner instantiates a Runner with a new chip8 using the given quirks and options, which will receive the key events of the script.
+//The events must be sorted by cycle, as ParseKeyEvents returns them.
+func NewRunner(quirks chip8.Quirks, events []KeyEvent, options ...chip8.Option) (*Runner, error) {
+	r := &Runner{
+		keypad:  &chip8.KeypadState{},
+		events:  events,
+		IPF:     chip8.DefaultIPF,
+		Palette: monitor.DefaultPalette,
+	}
+	var err error
+	r.c8, err = chip8.NewChip8(r.keypad, quirks, options...)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//Chip8 returns the chip8 executed by the runner, to load the ROM and the fonts into it, and read its state
+func (r *Runner) Chip8() *chip8.Chip8 {
+	return r.c8
+}
+
+//Cycles returns the number of cycles executed by the runner
+func (r *Runner) Cycles() uint64 {
+	return r.cycle
+}
+
+//Run executes n cycles, or less if the program exits. If the program makes the chip8 fail, Run returns the *chip8.Fault.
+//Cycle is called after every cycle, and after every IPF cycles a frame ends, Frame is called and the timers are ticked.
+//It returns ErrIPF if IPF is less than 1.
+func (r *Runner) Run(n uint64) error {
+	if r.IPF <= 0 {
+		return ErrIPF
+	}
+	for end := r.cycle + n; r.cycle < end && !r.c8.IsClosed(); {
+		r.pressKeys()
+		if err := r.c8.Cycle(); err != nil {
+			return err
+		}
+		r.cycle++
+		if r.Cycle != nil {
+			r.Cycle()
+		}
+		if r.cycle%uint64(r.IPF) == 0 {
+			if r.Frame != nil {
+				r.Frame()
+			}
+			r.c8.TickTimers()
+		}
+	}
+	return nil
+}
+
+//RunUntilStable executes frames until the frame buffer doesn't change for stable frames in a row, the program exits or n cycles are executed.
+//It returns whether the frame buffer became stable. If the program makes the chip8 fail, it returns the *chip8.Fault.
+func (r *Runner) RunUntilStable(stable int, n uint64) (bool, error) {
+	if r.IPF <= 0 {
+		return false, ErrIPF
+	}
+	previous := r.c8.GetFrameBuffer()
+	same := 0
+	for end := r.cycle + n; r.cycle < end && !r.c8.IsClosed(); {
+		if err := r.Run(uint64(r.IPF) - r.cycle%uint64(r.IPF)); err != nil {
+			return false, err
+		}
+		if fb := r.c8.GetFrameBuffer(); fb != previous {
+			previous, same = fb, 0
+			continue
+		}
+		if same++; same >= stable {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//pressKeys presses and releases the keys of the events of the current cycle
+func (r *Runner) pressKeys() {
+	for len(r.events) > 0 && r.events[0].Cycle <= r.cycle {
+		if e := r.events[0]; e.Down {
+			r.keypad.Press(e.Key)
+		} else {
+			r.keypad.Release(e.Key)
+		}
+		r.events = r.events[1:]
+	}
+}
+
+//WriteASCII writes the frame buffer of the chip8 as text, with a character per pixel
+func (r *Runner) WriteASCII(w io.Writer) error {
+	fb := r.c8.GetFrameBuffer()
+	_, err := io.WriteString(w, fb.ASCII())
+	return err
+}
+
+//WritePNG writes the frame buffer of the chip8 as a PNG image, with a pixel per pixel of the display in the colours of the palette
+func (r *Runner) WritePNG(w io.Writer) error {
+	fb := r.c8.GetFrameBuffer()
+	return png.Encode(w, fb.Image(r.Palette))
+}
+
+//WriteState writes the state of the chip8 as the JSON of a state.StateChip8
+func (r *Runner) WriteState(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.c8.Dump())
+}
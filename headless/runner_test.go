@@ -0,0 +1,118 @@
+package headless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/state"
+	"github.com/stretchr/testify/assert"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//waitKey is a ROM which waits for a key and draws its digit at (0,0)
+var waitKey = []byte{
+	0xF0, 0x0A, //200: LD V0, K
+	0xF0, 0x29, //202: LD F, V0
+	0x61, 0x00, //204: LD V1, #00
+	0xD1, 0x15, //206: DRW V1, V1, #5
+	0x12, 0x08, //208: JP #208
+}
+
+func newRunner(t *testing.T, rom []byte, events []KeyEvent) *Runner {
+	filename := filepath.Join(t.TempDir(), "rom.ch8")
+	assert.NoError(t, ioutil.WriteFile(filename, rom, 0644), "")
+	r, err := NewRunner(chip8.Quirks{}, events)
+	assert.NoError(t, err, "error in NewRunner")
+	assert.NoError(t, r.Chip8().LoadFonts("../assets/chip8.font"), "error in LoadFonts")
+	assert.NoError(t, r.Chip8().LoadROM(filename), "error in LoadROM")
+	return r
+}
+
+func TestParseKeyEvents(t *testing.T) {
+	events, err := ParseKeyEvents(strings.NewReader("; comment\n300 up a\n\n100 down A\n"))
+	assert.NoError(t, err, "")
+	assert.Equal(t, []KeyEvent{{Cycle: 100, Key: 0xA, Down: true}, {Cycle: 300, Key: 0xA}}, events, "sorted by cycle")
+
+	for _, script := range []string{"100 press 1", "x down 1", "100 down 10", "100 down"} {
+		_, err = ParseKeyEvents(strings.NewReader(script))
+		assert.Error(t, err, script)
+	}
+}
+
+func TestRunner_WaitsForKey(t *testing.T) {
+	r := newRunner(t, waitKey, []KeyEvent{{Cycle: 50, Key: 0xA, Down: true}, {Cycle: 60, Key: 0xA}})
+	assert.NoError(t, r.Run(55), "")
+	assert.Equal(t, uint64(55), r.Cycles(), "")
+	assert.Equal(t, uint16(0x200), r.Chip8().Registers().PC, "waiting for the key to be released")
+
+	assert.NoError(t, r.Run(45), "")
+	assert.Equal(t, byte(0xA), r.Chip8().Registers().V[0], "LD V0, K")
+
+	var ascii bytes.Buffer
+	assert.NoError(t, r.WriteASCII(&ascii), "")
+	rows := strings.Split(ascii.String(), "\n")
+	assert.Equal(t, 32+1, len(rows), "")
+	assert.Equal(t, "####....", rows[0][:8], "A")
+	assert.Equal(t, "#..#....", rows[1][:8], "A")
+	assert.Equal(t, 64, len(rows[0]), "")
+
+	var img bytes.Buffer
+	assert.NoError(t, r.WritePNG(&img), "")
+	decoded, err := png.Decode(&img)
+	assert.NoError(t, err, "")
+	assert.Equal(t, 64, decoded.Bounds().Dx(), "")
+	assert.Equal(t, 32, decoded.Bounds().Dy(), "")
+
+	var s bytes.Buffer
+	assert.NoError(t, r.WriteState(&s), "")
+	var decodedState state.StateChip8
+	assert.NoError(t, json.Unmarshal(s.Bytes(), &decodedState), "")
+	assert.Equal(t, uint16(0x208), decodedState.Pc, "")
+}
+
+func TestRunner_Fault(t *testing.T) {
+	r := newRunner(t, []byte{0x60, 0x01, 0xFF, 0xFF}, nil)
+	err := r.Run(10)
+	var fault *chip8.Fault
+	if assert.True(t, errors.As(err, &fault), "unknown opcode") {
+		assert.Equal(t, uint16(0x202), fault.PC, "")
+	}
+}
+
+func TestRunner_Exit(t *testing.T) {
+	r := newRunner(t, []byte{0x00, 0xFD}, nil)
+	assert.NoError(t, r.Run(10), "")
+	assert.Equal(t, uint64(1), r.Cycles(), "stops when the program exits")
+}
+
+func TestRunner_IPF(t *testing.T) {
+	r := newRunner(t, waitKey, nil)
+	r.IPF = 0
+	assert.Equal(t, ErrIPF, r.Run(10), "")
+	_, err := r.RunUntilStable(3, 100)
+	assert.Equal(t, ErrIPF, err, "")
+	assert.Equal(t, uint64(0), r.Cycles(), "")
+}
+
+func TestRunner_SelectSuiteTest(t *testing.T) {
+	vip, err := chip8.QuirksProfile("vip")
+	assert.NoError(t, err, "")
+	r, err := NewRunner(vip, nil)
+	assert.NoError(t, err, "")
+	assert.NoError(t, r.Chip8().LoadFonts("../assets/chip8.font"), "")
+	assert.NoError(t, r.Chip8().LoadROM("../assets/chip8-test-suite.ch8"), "")
+	r.SelectSuiteTest(1, "vip")
+	assert.NoError(t, r.Run(5000), "")
+
+	var ascii bytes.Buffer
+	assert.NoError(t, r.WriteASCII(&ascii), "")
+	rows := strings.Split(ascii.String(), "\n")
+	assert.Equal(t, "............########.#########...#####.........#####............", rows[8], "the IBM logo")
+	assert.Equal(t, byte(1), SuitePlatform("VIP"), "")
+	assert.Equal(t, byte(3), SuitePlatform("xochip"), "")
+}
@@ -0,0 +1,121 @@
+package headless
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+//Timendus' CHIP-8 test suite reads the test to run from SuiteTestAddress, instead of showing its menu,
+//and the platform of the quirks test from SuitePlatformAddress
+const (
+	SuiteTestAddress     = 0x1FF
+	SuitePlatformAddress = 0x1FE
+)
+
+//SuiteStableFrames is how many frames, 2 seconds, the screen of a test of the suite stays the same when the test has finished
+const SuiteStableFrames = 120
+
+//SuiteTests are the names of the tests of the suite, by number
+var SuiteTests = map[int]string{
+	1: "IBM logo",
+	2: "Corax+ opcode test",
+	3: "Flags test",
+	4: "Quirks test",
+	5: "Keypad test",
+}
+
+//SuitePlatform returns the platform chosen in the quirks test of the suite for a quirks profile:
+//1 for the CHIP-8, 2 for the SUPER-CHIP and 3 for the XO-CHIP
+func SuitePlatform(profile string) byte {
+	switch strings.ToLower(profile) {
+	case "schip", "chip48":
+		return 2
+	case "xochip":
+		return 3
+	}
+	return 1
+}
+
+//SelectSuiteTest makes the test suite loaded in the chip8 run the given test without showing its menu.
+//The quirks test checks the platform of the quirks profile.
+func (r *Runner) SelectSuiteTest(test int, profile string) {
+	r.c8.WriteMemory(SuiteTestAddress, byte(test))
+	r.c8.WriteMemory(SuitePlatformAddress, SuitePlatform(profile))
+}
+
+//SuiteCheck is a check of a test of the suite, whose name and result are shown in an area of the screen
+type SuiteCheck struct {
+	Name string
+	Area image.Rectangle
+}
+
+//SuiteChecks are the checks shown by the tests of the suite, by number of test. The IBM logo is a single check of the whole screen.
+//The Corax+ opcode test shows the opcodes in three columns, the flags test shows the result and vF of the arithmetic opcodes
+//without a carry and with it, and the quirks test shows a quirk per row.
+var SuiteChecks = map[int][]SuiteCheck{
+	1: {{"IBM logo", image.Rect(0, 0, 64, 32)}},
+	2: grid([]string{
+		"3XNN", "00EE", "8XY5",
+		"4XNN", "8XY0", "8XYE",
+		"5XY0", "8XY1", "8XY6",
+		"7XNN", "8XY2", "FX55",
+		"9XY0", "8XY3", "FX33",
+		"2NNN", "8XY4", "1NNN",
+	}, []int{0, 23, 46, 64}, []int{0, 5, 10, 15, 20, 25, 32}),
+	3: grid([]string{
+		"", "8XY1", "8XY2",
+		"8XY3", "8XY4", "8XY5",
+		"8XY6", "8XY7", "8XYE",
+		"", "8XY4 carry", "8XY5 carry",
+		"8XY6 carry", "8XY7 carry", "8XYE carry",
+		"", "FX1E overflow", "",
+	}, []int{0, 22, 44, 64}, []int{0, 5, 10, 16, 21, 27, 32}),
+	4: grid([]string{
+		"vF reset",
+		"memory",
+		"display wait",
+		"clipping",
+		"shifting",
+		"jumping",
+	}, []int{0, 64}, []int{0, 5, 10, 15, 20, 25, 32}),
+}
+
+//grid returns the checks shown in a grid, with their names row by row, given the edges of its columns and its rows.
+//The cells without a name are empty or have the title of a group of checks.
+func grid(names []string, columns, rows []int) []SuiteCheck {
+	var checks []SuiteCheck
+	for k, name := range names {
+		if name == "" {
+			continue
+		}
+		column, row := k%(len(columns)-1), k/(len(columns)-1)
+		checks = append(checks, SuiteCheck{name, image.Rect(columns[column], rows[row], columns[column+1], rows[row+1])})
+	}
+	return checks
+}
+
+//FailedChecks compares the screen of a test of the suite with the expected one, and returns the names of the checks whose areas differ.
+//If the screens differ out of the checks, or the test doesn't have any, "screen" is returned too.
+func FailedChecks(test int, got, want image.Image) []string {
+	var failed []string
+	differs := func(area image.Rectangle) bool {
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				if color.RGBAModel.Convert(got.At(x, y)) != color.RGBAModel.Convert(want.At(x, y)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, check := range SuiteChecks[test] {
+		if differs(check.Area) {
+			failed = append(failed, check.Name)
+		}
+	}
+	if got.Bounds() != want.Bounds() || (len(failed) == 0 && differs(want.Bounds())) {
+		failed = append(failed, "screen")
+	}
+	return failed
+}
@@ -0,0 +1,175 @@
+package headless
+
+import (
+	"flag"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//update rewrites the golden images of the test suite with the screens of the chip8: go test ./headless -run TestSuite -update
+var update = flag.Bool("update", false, "rewrite the golden images of the test suite")
+
+//suiteProfiles are the quirks profiles whose screens of the test suite have golden images in testdata/suite
+var suiteProfiles = []string{"vip", "chip48", "schip", "xochip"}
+
+//TestSuite runs the tests of Timendus' test suite with every quirks profile, and compares their screens with the golden images.
+//The golden images are checked to be the screens of the suite when the tests pass, so they don't just keep what the chip8 draws.
+//The keypad test is run by TestSuite_Keypad, it doesn't have checks.
+func TestSuite(t *testing.T) {
+	for _, profile := range suiteProfiles {
+		for test := 1; test <= 4; test++ {
+			profile, test := profile, test
+			t.Run(fmt.Sprintf("%s/%d", profile, test), func(t *testing.T) {
+				quirks, err := chip8.QuirksProfile(profile)
+				assert.NoError(t, err, "")
+				r, err := NewRunner(quirks, nil)
+				assert.NoError(t, err, "error in NewRunner")
+				assert.NoError(t, r.Chip8().LoadROM("../assets/chip8-test-suite.ch8"), "error in LoadROM")
+				r.SelectSuiteTest(test, profile)
+				stable, err := r.RunUntilStable(SuiteStableFrames, 1000000)
+				assert.NoError(t, err, "")
+				assert.True(t, stable, "the test doesn't finish")
+
+				fb := r.Chip8().GetFrameBuffer()
+				got := fb.Image(monitor.DefaultPalette)
+				golden := filepath.Join("testdata", "suite", profile, fmt.Sprintf("%d.png", test))
+				if *update {
+					assert.NoError(t, os.MkdirAll(filepath.Dir(golden), 0755), "")
+					f, err := os.Create(golden)
+					assert.NoError(t, err, "")
+					assert.NoError(t, png.Encode(f, got), "")
+					assert.NoError(t, f.Close(), "")
+				}
+
+				f, err := os.Open(golden)
+				if !assert.NoError(t, err, "the golden image is missing, write it with -update") {
+					return
+				}
+				defer f.Close()
+				want, err := png.Decode(f)
+				assert.NoError(t, err, "")
+				for _, check := range SuiteChecks[test] {
+					if test > 1 && (!hasGlyph(want, check.Area, suitePass[test == 2]) || hasGlyph(want, check.Area, suiteFail[test == 2])) {
+						t.Errorf("%d. %s (%s): %s doesn't pass in %s", test, SuiteTests[test], profile, check.Name, golden)
+					}
+				}
+				for _, name := range FailedChecks(test, got, want) {
+					t.Errorf("%d. %s (%s): %s differs from %s\n%s", test, SuiteTests[test], profile, name, golden, fb.ASCII())
+				}
+			})
+		}
+	}
+}
+
+//suitePass and suiteFail are the glyphs which the suite draws next to a check which passes and next to one which fails:
+//OK and NO in the Corax+ opcode test (true), and a checkmark and a cross in the flags and the quirks tests (false)
+var (
+	suitePass = map[bool][]string{
+		true:  {"###.#.#", "#.#.##.", "#.#.#.#", "###.#.#"},
+		false: {"#.#", "##.", "#.."},
+	}
+	suiteFail = map[bool][]string{
+		true:  {"##..###", "#.#.#.#", "#.#.#.#", "#.#.###"},
+		false: {"#.#", ".#.", "#.#"},
+	}
+)
+
+//hasGlyph reports whether the glyph, written with a # per pixel on and a . per pixel off, is drawn somewhere in the area of the screen.
+//The pixels around the glyph must be off, or out of the screen, so it isn't found in a part of a bigger one.
+func hasGlyph(screen image.Image, area image.Rectangle, glyph []string) bool {
+	off := color.RGBAModel.Convert(monitor.DefaultPalette[0])
+	matches := func(x, y int) bool {
+		for dy := -1; dy <= len(glyph); dy++ {
+			for dx := -1; dx <= len(glyph[0]); dx++ {
+				on := dy >= 0 && dy < len(glyph) && dx >= 0 && dx < len(glyph[0]) && glyph[dy][dx] == '#'
+				p := image.Pt(x+dx, y+dy)
+				if (p.In(screen.Bounds()) && color.RGBAModel.Convert(screen.At(p.X, p.Y)) != off) != on {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for y := area.Min.Y; y+len(glyph) <= area.Max.Y; y++ {
+		for x := area.Min.X; x+len(glyph[0]) <= area.Max.X; x++ {
+			if matches(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//TestSuite_Keypad runs the keypad test of the suite, which draws the keypad and inverts the keys held down, pressing every key with a script
+func TestSuite_Keypad(t *testing.T) {
+	screen := func(events []KeyEvent) monitor.FrameBuffer {
+		r, err := NewRunner(chip8.Quirks{}, events)
+		assert.NoError(t, err, "")
+		assert.NoError(t, r.Chip8().LoadROM("../assets/chip8-test-suite.ch8"), "")
+		r.SelectSuiteTest(5, "")
+		assert.NoError(t, r.Run(6000), "")
+		return r.Chip8().GetFrameBuffer()
+	}
+	idle := screen(nil)
+	//The keys are drawn in 7x6 cells, like the keypad of the COSMAC VIP
+	layout := []byte{0x1, 0x2, 0x3, 0xC, 0x4, 0x5, 0x6, 0xD, 0x7, 0x8, 0x9, 0xE, 0xA, 0x0, 0xB, 0xF}
+	for k, key := range layout {
+		held := screen([]KeyEvent{{Cycle: 1000, Key: key, Down: true}})
+		cell := image.Rect(16+8*(k%4), 2+7*(k/4), 23+8*(k%4), 8+7*(k/4))
+		var wrong []image.Point
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 64; x++ {
+				if inverted := *held.Get(x, y) != *idle.Get(x, y); inverted != image.Pt(x, y).In(cell) {
+					wrong = append(wrong, image.Pt(x, y))
+				}
+			}
+		}
+		assert.Empty(t, wrong, "key %X: only its cell is inverted\n%s", key, held.ASCII())
+	}
+
+	released := screen([]KeyEvent{{Cycle: 1000, Key: 0xA, Down: true}, {Cycle: 3000, Key: 0xA}})
+	assert.Equal(t, idle.ASCII(), released.ASCII(), "the key isn't inverted after it's released")
+}
+
+//TestSuite_Fail runs the quirks test of the CHIP-8 without its quirks, to check that the glyph of a failed check is recognized
+func TestSuite_Fail(t *testing.T) {
+	r, err := NewRunner(chip8.Quirks{}, nil)
+	assert.NoError(t, err, "")
+	assert.NoError(t, r.Chip8().LoadROM("../assets/chip8-test-suite.ch8"), "")
+	r.SelectSuiteTest(4, "vip")
+	_, err = r.RunUntilStable(SuiteStableFrames, 1000000)
+	assert.NoError(t, err, "")
+	fb := r.Chip8().GetFrameBuffer()
+	screen := fb.Image(monitor.DefaultPalette)
+	var failed []string
+	for _, check := range SuiteChecks[4] {
+		if hasGlyph(screen, check.Area, suiteFail[false]) {
+			failed = append(failed, check.Name)
+		}
+		assert.NotEqual(t, hasGlyph(screen, check.Area, suitePass[false]), hasGlyph(screen, check.Area, suiteFail[false]), check.Name)
+	}
+	assert.Equal(t, []string{"vF reset", "memory", "display wait", "shifting"}, failed, fb.ASCII())
+}
+
+func TestFailedChecks(t *testing.T) {
+	var fb monitor.FrameBuffer
+	want := fb.Image(monitor.DefaultPalette)
+	*fb.Get(30, 12) = 1
+	*fb.Get(60, 31) = 1
+	got := fb.Image(monitor.DefaultPalette)
+	assert.Equal(t, []string{"8XY1", "1NNN"}, FailedChecks(2, got, want), "")
+	assert.Equal(t, []string{"IBM logo"}, FailedChecks(1, got, want), "")
+
+	*fb.Get(30, 12) = 0
+	got = fb.Image(monitor.DefaultPalette)
+	assert.Equal(t, []string{"screen"}, FailedChecks(3, got, want), "a pixel out of the checks")
+	assert.Empty(t, FailedChecks(3, want, want), "")
+}
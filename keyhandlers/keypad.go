@@ -1,7 +1,10 @@
 package keyhandlers
 
 import (
+	"fmt"
 	"github.com/faiface/pixel/pixelgl"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,7 +15,8 @@ type KeyHandler interface {
 }
 type keyHandler struct {
 	*pixelgl.Window
-	cmd *Cmd
+	cmd     *Cmd
+	pressed map[pixelgl.Button]bool
 }
 
 type Cmd map[pixelgl.Button]func()
@@ -23,7 +27,7 @@ type Cmd map[pixelgl.Button]func()
 //	|Q|W|E|R|         |4|5|6|D|
 //	|A|S|D|F|         |7|8|9|E|
 //	|Z|X|C|V|         |A|0|B|F|
-var KeyboardToKeypad = map[pixelgl.Button]byte{
+var KeyboardToKeypad = KeyMap{
 	pixelgl.Key1: 1,
 	pixelgl.Key2: 2,
 	pixelgl.Key3: 3,
@@ -42,6 +46,40 @@ var KeyboardToKeypad = map[pixelgl.Button]byte{
 	pixelgl.KeyV: 0xF,
 }
 
+//KeyMap maps keys of the keyboard to keys of the keypad
+type KeyMap map[pixelgl.Button]byte
+
+//ParseKeyMap returns KeyboardToKeypad with the keys of keys added to it, replacing the ones it has.
+//keys maps names of keys of the keyboard, such as "Up", "Space" or "W", to keys of the keypad written in hexadecimal.
+func ParseKeyMap(keys map[string]string) (KeyMap, error) {
+	keyMap := KeyMap{}
+	for button, key := range KeyboardToKeypad {
+		keyMap[button] = key
+	}
+	for name, value := range keys {
+		button, ok := buttonByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown key '%s'", name)
+		}
+		key, err := strconv.ParseUint(value, 16, 8)
+		if err != nil || key > 0xF {
+			return nil, fmt.Errorf("'%s' is not a key of the keypad, expected a hexadecimal digit", value)
+		}
+		keyMap[button] = byte(key)
+	}
+	return keyMap, nil
+}
+
+//buttonByName returns the key of the keyboard with the name given by pixelgl, ignoring the case
+func buttonByName(name string) (pixelgl.Button, bool) {
+	for button := pixelgl.KeySpace; button <= pixelgl.KeyLast; button++ {
+		if strings.EqualFold(button.String(), name) {
+			return button, true
+		}
+	}
+	return 0, false
+}
+
 //NewKeyHandler receives a Window to embed, and a map with keys to handler
 func NewKeyHandler(window *pixelgl.Window, cmd *Cmd) KeyHandler {
 	keyHandler := new(keyHandler)
@@ -50,23 +88,96 @@ func NewKeyHandler(window *pixelgl.Window, cmd *Cmd) KeyHandler {
 	return keyHandler
 }
 
-//ExecuteInputs checks which keys of the command map have been pressed and executes them
+//ExecuteInputs checks which keys of the command map have been pressed and executes them.
+//A command is executed once per press, when its key goes down, however many times the keyboard is polled while it's held
 func (kHandler *keyHandler) ExecuteInputs() {
-	for true {
-		clock := time.NewTicker((time.Second / time.Duration(500)) * 2)
-		select {
-		case <-clock.C:
-			{
-				for key, c := range *kHandler.cmd {
-					if kHandler.JustPressed(key) {
-						c()
-
-					}
-
-				}
+	clock := time.NewTicker((time.Second / time.Duration(500)) * 2)
+	defer clock.Stop()
+	kHandler.pressed = make(map[pixelgl.Button]bool)
+	for range clock.C {
+		for key, c := range *kHandler.cmd {
+			down := kHandler.Pressed(key)
+			if down && !kHandler.pressed[key] {
+				c()
 			}
+			kHandler.pressed[key] = down
 		}
+	}
+}
+
+//KeypadWriter receives the keys of the keypad pressed and released with the keyboard
+type KeypadWriter interface {
+	Press(key byte)
+	Release(key byte)
+}
+
+type keypadHandler struct {
+	*pixelgl.Window
+	keypad KeypadWriter
+	keyMap KeyMap
+}
+
+//NewKeypadHandler receives a Window to embed, the keypad in which the keys are pressed and released,
+//and the map of the keys of the keyboard to the keys of the keypad, KeyboardToKeypad if it's nil
+func NewKeypadHandler(window *pixelgl.Window, keypad KeypadWriter, keyMap KeyMap) KeyHandler {
+	kHandler := new(keypadHandler)
+	kHandler.Window = window
+	kHandler.keypad = keypad
+	kHandler.keyMap = keyMap
+	if keyMap == nil {
+		kHandler.keyMap = KeyboardToKeypad
+	}
+	return kHandler
+}
 
+//ExecuteInputs checks which keys of the keypad are held down, and updates the state of the keypad
+func (kHandler *keypadHandler) ExecuteInputs() {
+	clock := time.NewTicker((time.Second / time.Duration(500)) * 2)
+	for range clock.C {
+		var pressed uint16
+		for button, key := range kHandler.keyMap {
+			if kHandler.Pressed(button) {
+				pressed |= 1 << key
+			}
+		}
+		for key := byte(0); key < 16; key++ {
+			if pressed&(1<<key) != 0 {
+				kHandler.keypad.Press(key)
+			} else {
+				kHandler.keypad.Release(key)
+			}
+		}
 	}
+}
+
+//DebugCmd is a command of the debugger which can be given with the keyboard
+type DebugCmd byte
+
+const (
+	DebugContinue   DebugCmd = iota //Continues the chip8 if it's paused, or pauses it otherwise
+	DebugStep                       //Executes a single instruction
+	DebugStepOver                   //Executes a single instruction, running the whole subroutine if it's a call
+	DebugStepOut                    //Runs until the current subroutine returns
+	DebugBreakpoint                 //Toggles a breakpoint at the current address
+	DebugOverlay                    //Shows or hides the registers drawn over the screen
+)
 
+//KeyboardToDebugger maps the keys of a computer keyboard which are not used by the keypad to the commands of the debugger:
+//	P continue/pause, N step, M step over, U step out, B toggle breakpoint, O toggle overlay
+var KeyboardToDebugger = map[pixelgl.Button]DebugCmd{
+	pixelgl.KeyP: DebugContinue,
+	pixelgl.KeyN: DebugStep,
+	pixelgl.KeyM: DebugStepOver,
+	pixelgl.KeyU: DebugStepOut,
+	pixelgl.KeyB: DebugBreakpoint,
+	pixelgl.KeyO: DebugOverlay,
 }
+
+//KeyRewind is the key which runs the emulation backwards, a frame per frame, while it's held down
+const KeyRewind = pixelgl.KeyBackspace
+
+//KeyScreenshot is the key which saves a screenshot of the display
+const KeyScreenshot = pixelgl.KeyF12
+
+//KeyRecord is the key which starts recording the display, and stops the recording if it's being recorded
+const KeyRecord = pixelgl.KeyF11
@@ -1,35 +1,62 @@
 package main
 
 import (
+	"fmt"
 	"github.com/NoetherianRing/Chip-8/app"
 	"github.com/NoetherianRing/Chip-8/config"
+	"github.com/NoetherianRing/Chip-8/movie"
 	"github.com/faiface/pixel/pixelgl"
-	"gopkg.in/yaml.v2"
-	"math/rand"
 	"os"
-	"time"
 )
 
+//windowCfg is the configuration of the app run in the window, startState is a save state loaded after its ROM,
+//playMovie and movieFile are the movie played and the file of the movie recorded, and windowErr is the error that stopped the app from running
+var (
+	windowCfg  config.Config
+	startState string
+	playMovie  *movie.Movie
+	movieFile  string
+	windowErr  error
+)
+
+//runWindow runs the app in a window with the given configuration, loading the save state if it's not empty,
+//playing the movie play if it isn't nil and recording a movie into record if it isn't empty.
+//The window must be managed by the main goroutine, so the app runs inside pixelgl.Run.
+func runWindow(cfg config.Config, state string, play *movie.Movie, record string) error {
+	windowCfg, startState, playMovie, movieFile = cfg, state, play, record
+	pixelgl.Run(run)
+	return windowErr
+}
+
 func run() {
-	rand.Seed(time.Now().UnixMilli())
-	f, err := os.Open("config.yml")
+	myApp, err := app.NewApp(windowCfg)
 	if err != nil {
-		panic(err)
+		windowErr = err
+		return
 	}
-	defer f.Close()
-	decoder := yaml.NewDecoder(f)
-	var cfg config.Config
-	err = decoder.Decode(&cfg)
-	if err != nil {
-		panic(err)
+	if startState != "" {
+		myApp.SetStartState(startState)
 	}
-
-	myApp, err := app.NewApp(cfg)
-	if err != nil {
-		panic(err)
+	if playMovie != nil {
+		myApp.PlayMovie(playMovie)
+	}
+	if movieFile != "" {
+		myApp.RecordMovie(movieFile)
 	}
 	myApp.Run()
 }
+
 func main() {
-	pixelgl.Run(run)
+	command, args := runROM, []string(nil)
+	if len(os.Args) > 1 {
+		command, args = commands[os.Args[1]], os.Args[2:]
+	}
+	if command == nil {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := command(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
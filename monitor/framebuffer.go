@@ -0,0 +1,101 @@
+package monitor
+
+const (
+	LowResWidth   = 64  //Width of the display of the original chip8
+	LowResHeight  = 32  //Height of the display of the original chip8
+	HighResWidth  = 128 //Width of the SUPER-CHIP high resolution mode
+	HighResHeight = 64  //Height of the SUPER-CHIP high resolution mode
+	AllPlanes     = 0x3 //XO-CHIP has two bitplanes, each pixel has one bit per plane
+)
+
+//FrameBuffer represents the display of the chip8. It has 64x32 pixels, or 128x64 pixels when the high resolution mode of the SUPER-CHIP is on.
+//Each element of Pixels represents a pixel, the rows are stored one after the other using the width of the current resolution.
+//The bit 0 of a pixel is its value in the first plane and the bit 1 its value in the second plane of XO-CHIP,
+//so a pixel can take four values, which the monitor draws with four colours. Programs that don't use XO-CHIP only use the first plane.
+type FrameBuffer struct {
+	Pixels  [HighResWidth * HighResHeight]byte
+	HighRes bool
+}
+
+//Width returns the number of columns of the current resolution
+func (f *FrameBuffer) Width() int {
+	if f.HighRes {
+		return HighResWidth
+	}
+	return LowResWidth
+}
+
+//Height returns the number of rows of the current resolution
+func (f *FrameBuffer) Height() int {
+	if f.HighRes {
+		return HighResHeight
+	}
+	return LowResHeight
+}
+
+//CheckOverlap checks if the given x, y are outside the bounds of the display
+func (f *FrameBuffer) CheckOverlap(x, y int) bool {
+	if x < 0 || x >= f.Width() || y < 0 || y >= f.Height() {
+		return true
+	} else {
+		return false
+	}
+}
+
+//Get returns the cell of the FrameBuffer corresponded to the (x,y) coordinate of the display
+func (f *FrameBuffer) Get(x, y int) *byte {
+	return &f.Pixels[y*f.Width()+x]
+}
+
+//Clear turns off every pixel
+func (f *FrameBuffer) Clear() {
+	f.Pixels = [HighResWidth * HighResHeight]byte{}
+}
+
+//ClearPlanes turns off every pixel of the given planes
+func (f *FrameBuffer) ClearPlanes(planes byte) {
+	for k := range f.Pixels {
+		f.Pixels[k] &^= planes
+	}
+}
+
+//SetHighRes switches between the 64x32 and the 128x64 resolutions, clearing the display
+func (f *FrameBuffer) SetHighRes(highRes bool) {
+	f.HighRes = highRes
+	f.Clear()
+}
+
+//ScrollDown moves every pixel of the given planes n rows down, the rows that appear on top are off
+func (f *FrameBuffer) ScrollDown(n int, planes byte) {
+	f.scroll(0, n, planes)
+}
+
+//ScrollUp moves every pixel of the given planes n rows up, the rows that appear on the bottom are off
+func (f *FrameBuffer) ScrollUp(n int, planes byte) {
+	f.scroll(0, -n, planes)
+}
+
+//ScrollRight moves every pixel of the given planes n columns to the right, the columns that appear on the left are off
+func (f *FrameBuffer) ScrollRight(n int, planes byte) {
+	f.scroll(n, 0, planes)
+}
+
+//ScrollLeft moves every pixel of the given planes n columns to the left, the columns that appear on the right are off
+func (f *FrameBuffer) ScrollLeft(n int, planes byte) {
+	f.scroll(-n, 0, planes)
+}
+
+//scroll moves every pixel of the given planes dx columns and dy rows, the pixels of the other planes stay where they are
+func (f *FrameBuffer) scroll(dx, dy int, planes byte) {
+	scrolled := *f
+	scrolled.ClearPlanes(planes)
+	for y := 0; y < f.Height(); y++ {
+		for x := 0; x < f.Width(); x++ {
+			if scrolled.CheckOverlap(x+dx, y+dy) {
+				continue
+			}
+			*scrolled.Get(x+dx, y+dy) |= *f.Get(x, y) & planes
+		}
+	}
+	*f = scrolled
+}
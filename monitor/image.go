@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"image"
+	"strings"
+)
+
+//asciiPixels are the characters used by ASCII for each value of a pixel: off, first plane, second plane and both planes
+const asciiPixels = ".#o@"
+
+//ASCII draws the FrameBuffer as text, one line per row, with a character per pixel
+func (f *FrameBuffer) ASCII() string {
+	var sb strings.Builder
+	for y := 0; y < f.Height(); y++ {
+		for x := 0; x < f.Width(); x++ {
+			sb.WriteByte(asciiPixels[*f.Get(x, y)&AllPlanes])
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+//Image draws the FrameBuffer into an image with a pixel per pixel of the display, using the colours of the palette
+func (f *FrameBuffer) Image(palette Palette) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, f.Width(), f.Height()))
+	for y := 0; y < f.Height(); y++ {
+		for x := 0; x < f.Width(); x++ {
+			img.SetRGBA(x, y, palette[*f.Get(x, y)&AllPlanes])
+		}
+	}
+	return img
+}
@@ -4,64 +4,84 @@ import (
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
-	"golang.org/x/image/colornames"
+	"github.com/faiface/pixel/text"
+	"image/color"
 )
 
 const (
-	SidePixel    = 16
-	WidthScreen  = SidePixel * 64
-	HeightScreen = SidePixel * 32
+	SidePixel    = 16 //Side of a pixel of the 64x32 display in a window of the default size, the pixels of the 128x64 display are half of it
+	WidthScreen  = SidePixel * LowResWidth
+	HeightScreen = SidePixel * LowResHeight
 )
 
-type FrameBuffer [64 * 32]byte
-
-//CheckOverlap checks if the given x, y are outside the bounds of the array
-func (f *FrameBuffer) CheckOverlap(x, y int) bool {
-	if y*64+x >= len(f) {
-		return true
-	} else {
-		return false
-	}
-}
-
-//Get returns the cell of the FrameBuffer corresponded to the (x,y) coordinate of the 64x32 display
-func (f *FrameBuffer) Get(x, y int) *byte {
-	return &f[y*64+x]
-}
-
 type Monitor interface {
 	ToDraw(buffer FrameBuffer)
+	ToDrawText(message string)
+}
+
+//Style is how the monitor draws the pixels
+type Style struct {
+	Palette  Palette
+	Grid     float64 //Gap in pixels of the window between the pixels of the display, 0 for none
+	Phosphor float64 //Persistence of the pixels which are turned off, between 0 (none) and 1, see Phosphor
 }
 
 type monitor struct {
 	*pixelgl.Window
+	palette  Palette
+	grid     float64
+	phosphor *Phosphor //nil if the style doesn't have persistence
 }
 
-func NewMonitor(window *pixelgl.Window) Monitor {
+//NewMonitor instantiates a Monitor which draws in the window with the given style
+func NewMonitor(window *pixelgl.Window, style Style) Monitor {
 	m := new(monitor)
 	m.Window = window
+	m.palette = style.Palette
+	m.grid = style.Grid
+	if style.Phosphor > 0 {
+		m.phosphor = NewPhosphor(style.Phosphor)
+	}
 	return m
 }
 
 //ToDraw reads the FrameBuffer of the chip8.
-//Every element in FrameBuffer represents a pixel on the screen which can be on or off.
-//If it's on ToDraw draws a 16x16 "pixel" on the screen
+//Every element in FrameBuffer represents a pixel on the screen which can be on or off in each plane.
+//If it's on in any plane ToDraw draws a square "pixel" on the screen, 16x16 in a window of the default size or a 8x8 one when the FrameBuffer
+//is in high resolution, with the colour of the palette that corresponds to the planes in which it's on.
+//The pixels are scaled to fill the window, leaving the gap of the grid of the style between them.
+//If the style has persistence, every call to ToDraw is a new frame, in which the pixels turned off keep fading out.
 func (m *monitor) ToDraw(buffer FrameBuffer) {
-	m.Clear(colornames.Black)
+	m.Clear(m.palette[0])
 	imd := imdraw.New(nil)
-	imd.Color = pixel.RGB(1, 1, 1)
+
+	width, height := buffer.Width(), buffer.Height()
+	side := m.Bounds().W() / float64(width)
+	gap := m.grid
+	if gap >= side {
+		gap = side - 1
+	}
+	var shown []color.RGBA
+	if m.phosphor != nil {
+		shown = m.phosphor.Filter(&buffer, m.palette)
+	}
 
 	//Chip8 has a coordinate system in which the (0,0) is at the upper left corner of the screen
 	//Pixelgls a coordinate system in which the (0,0) is at the lower left corner of the screen
-	//that's why we get the element (x, 31-y) of the buffer instead of the element (x,y)
-	for y := 0; y < 32; y++ {
-		for x := 0; x < 64; x++ {
-			if buffer.CheckOverlap(x, 31-y) {
+	//that's why we get the element (x, height-1-y) of the buffer instead of the element (x,y)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if buffer.CheckOverlap(x, height-1-y) {
 				continue
 			}
-			if *buffer.Get(x, 31-y) != 0 {
-				imd.Push(pixel.V(SidePixel*float64(x), SidePixel*float64(y)))
-				imd.Push(pixel.V(SidePixel*float64(x)+SidePixel, SidePixel*float64(y)+SidePixel))
+			c := m.palette[*buffer.Get(x, height-1-y)&AllPlanes]
+			if shown != nil {
+				c = shown[(height-1-y)*width+x]
+			}
+			if c != m.palette[0] {
+				imd.Color = c
+				imd.Push(pixel.V(side*float64(x), side*float64(y)+gap))
+				imd.Push(pixel.V(side*float64(x)+side-gap, side*float64(y)+side))
 				imd.Rectangle(0)
 			}
 		}
@@ -69,3 +89,24 @@ func (m *monitor) ToDraw(buffer FrameBuffer) {
 	imd.Draw(m)
 
 }
+
+//ToDrawText draws a message over the upper left corner of the screen, on top of what was drawn by ToDraw
+//The message is written with the colour of the first plane, in a box with the colour of the background.
+func (m *monitor) ToDrawText(message string) {
+	txt := text.New(pixel.V(0, 0), text.Atlas7x13)
+	txt.Color = m.palette[1]
+	_, _ = txt.WriteString(message)
+
+	const scale, margin = 2, 8
+	bounds := txt.Bounds()
+	top := m.Bounds().H()
+	matrix := pixel.IM.Moved(pixel.V(margin-bounds.Min.X, top-margin-bounds.Max.Y)).Scaled(pixel.V(0, top), scale)
+
+	imd := imdraw.New(nil)
+	imd.Color = m.palette[0]
+	imd.Push(matrix.Project(bounds.Min).Sub(pixel.V(margin, margin)), matrix.Project(bounds.Max).Add(pixel.V(margin, margin)))
+	imd.Rectangle(0)
+	imd.Draw(m)
+
+	txt.Draw(m, matrix)
+}
@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Palette has the colours used to draw each value of a pixel of the FrameBuffer:
+//the background, the first plane, the second plane and the pixels which are on in both planes
+type Palette [4]color.RGBA
+
+//DefaultPalette draws the first plane white on black, as the original chip8, and uses two greys for the second plane of XO-CHIP
+var DefaultPalette = Palette{
+	{R: 0x00, G: 0x00, B: 0x00, A: 0xFF},
+	{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+	{R: 0xAA, G: 0xAA, B: 0xAA, A: 0xFF},
+	{R: 0x55, G: 0x55, B: 0x55, A: 0xFF},
+}
+
+//presets are the palettes that can be selected by name in the configuration
+var presets = map[string]Palette{
+	"classic": DefaultPalette,
+	"amber": {
+		{R: 0x1A, G: 0x0F, B: 0x00, A: 0xFF},
+		{R: 0xFF, G: 0xB0, B: 0x00, A: 0xFF},
+		{R: 0xB3, G: 0x6B, B: 0x00, A: 0xFF},
+		{R: 0xFF, G: 0xD8, B: 0x66, A: 0xFF},
+	},
+	"green": {
+		{R: 0x00, G: 0x14, B: 0x00, A: 0xFF},
+		{R: 0x33, G: 0xFF, B: 0x33, A: 0xFF},
+		{R: 0x1F, G: 0x8F, B: 0x1F, A: 0xFF},
+		{R: 0xA6, G: 0xFF, B: 0xA6, A: 0xFF},
+	},
+	"lcd": {
+		{R: 0x9B, G: 0xBC, B: 0x0F, A: 0xFF},
+		{R: 0x0F, G: 0x38, B: 0x0F, A: 0xFF},
+		{R: 0x8B, G: 0xAC, B: 0x0F, A: 0xFF},
+		{R: 0x30, G: 0x62, B: 0x30, A: 0xFF},
+	},
+}
+
+//PresetPalette returns the preset with the given name: "classic", "amber", "green" (phosphor) or "lcd"
+func PresetPalette(name string) (Palette, error) {
+	palette, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return DefaultPalette, errors.New("unknown palette '" + name + "', expected one of: " + strings.Join(PresetPalettes(), ", "))
+	}
+	return palette, nil
+}
+
+//PresetPalettes returns the names of all the presets, sorted alphabetically
+func PresetPalettes() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//ParsePalette returns the palette of the preset, or DefaultPalette if preset is empty,
+//with up to 4 colours written in hexadecimal as #RRGGBB replacing its colours in order
+func ParsePalette(preset string, colors []string) (Palette, error) {
+	palette := DefaultPalette
+	if preset != "" {
+		var err error
+		if palette, err = PresetPalette(preset); err != nil {
+			return palette, err
+		}
+	}
+	if len(colors) > len(palette) {
+		return palette, fmt.Errorf("a palette has %d colours, not %d", len(palette), len(colors))
+	}
+	for k, c := range colors {
+		rgb, err := strconv.ParseUint(strings.TrimPrefix(c, "#"), 16, 32)
+		if err != nil || len(strings.TrimPrefix(c, "#")) != 6 {
+			return palette, fmt.Errorf("'%s' is not a colour, expected #RRGGBB", c)
+		}
+		palette[k] = color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xFF}
+	}
+	return palette, nil
+}
@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"image/color"
+	"testing"
+)
+
+func TestParsePalette(t *testing.T) {
+	palette, err := ParsePalette("", nil)
+	assert.NoError(t, err, "")
+	assert.Equal(t, DefaultPalette, palette, "")
+
+	palette, err = ParsePalette("Amber", []string{"#102030"})
+	assert.NoError(t, err, "")
+	assert.Equal(t, color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF}, palette[0], "the colours replace the ones of the preset")
+	assert.Equal(t, presets["amber"][1], palette[1], "")
+
+	for _, colors := range [][]string{{"#12345"}, {"red"}, {"#000000", "#000000", "#000000", "#000000", "#000000"}} {
+		_, err = ParsePalette("", colors)
+		assert.Error(t, err, "%v", colors)
+	}
+	_, err = ParsePalette("sepia", nil)
+	assert.Error(t, err, "")
+	assert.Equal(t, []string{"amber", "classic", "green", "lcd"}, PresetPalettes(), "")
+}
+
+func TestPhosphor(t *testing.T) {
+	var fb FrameBuffer
+	*fb.Get(1, 0) = 1
+	p := NewPhosphor(0.5)
+	shown := p.Filter(&fb, DefaultPalette)
+	assert.Equal(t, DefaultPalette[1], shown[1], "the pixels which are on are shown at once")
+	assert.False(t, p.Fading(), "")
+
+	*fb.Get(1, 0) = 0
+	shown = p.Filter(&fb, DefaultPalette)
+	assert.Equal(t, color.RGBA{R: 0x7F, G: 0x7F, B: 0x7F, A: 0xFF}, shown[1], "half of the colour is kept")
+	assert.True(t, p.Fading(), "")
+
+	frames := 1
+	for p.Fading() {
+		p.Filter(&fb, DefaultPalette)
+		frames++
+	}
+	assert.Equal(t, DefaultPalette[0], p.Filter(&fb, DefaultPalette)[1], "")
+	assert.Equal(t, 8, frames, "")
+
+	fb.SetHighRes(true)
+	assert.Equal(t, HighResWidth*HighResHeight, len(p.Filter(&fb, DefaultPalette)), "")
+}
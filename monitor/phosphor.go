@@ -0,0 +1,66 @@
+package monitor
+
+import "image/color"
+
+//Phosphor is a filter which emulates the persistence of the phosphor of a CRT: the pixels which are turned off fade out
+//over the next frames, instead of disappearing at once. It reduces the flicker of the programs which erase their sprites
+//and draw them again in the next frame, as most CHIP-8 programs do. The pixels which are on are shown at once.
+type Phosphor struct {
+	persistence float64 //Fraction of its colour in the last frame kept by a pixel which is off
+	shown       [HighResWidth * HighResHeight]color.RGBA
+	highRes     bool
+	started     bool
+	fading      bool
+}
+
+//NewPhosphor instantiates a Phosphor which keeps a fraction persistence of the colour of the pixels which are off every frame,
+//between 0 (no persistence) and 1 (excluded)
+func NewPhosphor(persistence float64) *Phosphor {
+	if persistence < 0 {
+		persistence = 0
+	}
+	if persistence > 0.99 {
+		persistence = 0.99
+	}
+	return &Phosphor{persistence: persistence}
+}
+
+//Filter returns the colours to show for the pixels of the frame buffer in a new frame, row by row.
+//The pixels which are off are blended with the colours shown in the last frame, and the ones which are on have the colour of the palette.
+func (p *Phosphor) Filter(buffer *FrameBuffer, palette Palette) []color.RGBA {
+	size := buffer.Width() * buffer.Height()
+	if !p.started || p.highRes != buffer.HighRes {
+		for k := range p.shown {
+			p.shown[k] = palette[0]
+		}
+		p.started, p.highRes = true, buffer.HighRes
+	}
+	p.fading = false
+	background := palette[0]
+	for k := 0; k < size; k++ {
+		if cell := buffer.Pixels[k] & AllPlanes; cell != 0 {
+			p.shown[k] = palette[cell]
+			continue
+		}
+		if p.shown[k] == background {
+			continue
+		}
+		p.shown[k] = p.blend(p.shown[k], background)
+		p.fading = p.fading || p.shown[k] != background
+	}
+	return p.shown[:size]
+}
+
+//Fading returns whether any pixel was still fading out in the last frame
+func (p *Phosphor) Fading() bool {
+	return p.fading
+}
+
+//blend moves the colour shown towards the background, keeping the persistence of the difference between them.
+//The difference is truncated, so it always decreases and the pixels end fading out.
+func (p *Phosphor) blend(shown, background color.RGBA) color.RGBA {
+	channel := func(s, b uint8) uint8 {
+		return uint8(int(b) + int(float64(int(s)-int(b))*p.persistence))
+	}
+	return color.RGBA{R: channel(shown.R, background.R), G: channel(shown.G, background.G), B: channel(shown.B, background.B), A: 0xFF}
+}
@@ -0,0 +1,177 @@
+package movie
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/headless"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	Magic   = "chip8-movie" //First word of the movies
+	Version = 3             //Version of the format of the movies
+)
+
+//Movie is a recording of the inputs of a session: the keys held down in every frame, with the seed of the random numbers and the settings of the chip8.
+//Playing it from the start of the same ROM reproduces the session bit by bit.
+type Movie struct {
+	ROM     string   //SHA-1 of the ROM in hexadecimal, as romdb.Hash returns it
+	Profile string   //Quirks profile
+	IPF     int      //Instructions executed per frame
+	Random  string   //Name of the random source of CXKK, as chip8.ParseRandom takes it
+	Seed    int64    //Seed of the random source
+	Data    []byte   //Bytes of the random source read from a file, as chip8.RandomData returns them, so the movie doesn't need the file
+	Frames  []uint16 //Keys held down in every frame, the bit k is set if the key k is held down
+}
+
+//New instantiates an empty movie of the chip8, with the seed of its random source, and its bytes if it reads them from a file
+func New(c8 *chip8.Chip8, rom, profile, random string, ipf int) *Movie {
+	if random == "" {
+		random = "prng"
+	}
+	return &Movie{ROM: rom, Profile: profile, IPF: ipf, Random: random, Seed: c8.RandomSeed(), Data: chip8.RandomData(c8.RandomSource())}
+}
+
+//RandomOption returns the option of the random source of the movie, with which the chip8 which plays it draws the same numbers
+func (m *Movie) RandomOption() (chip8.Option, error) {
+	return chip8.RandomOption(m.Random, m.Seed, m.Data)
+}
+
+//Events returns the key events which press and release the keys of the frames, at the first cycle of every frame,
+//so a headless.Runner plays the movie
+func (m *Movie) Events() []headless.KeyEvent {
+	var events []headless.KeyEvent
+	var keys uint16
+	for frame, next := range m.Frames {
+		for key := byte(0); key < chip8.NumberOfKeys; key++ {
+			if bit := uint16(1) << key; keys&bit != next&bit {
+				events = append(events, headless.KeyEvent{Cycle: uint64(frame * m.IPF), Key: key, Down: next&bit != 0})
+			}
+		}
+		keys = next
+	}
+	return events
+}
+
+//Cycles returns the number of cycles of the movie
+func (m *Movie) Cycles() uint64 {
+	return uint64(len(m.Frames) * m.IPF)
+}
+
+//Write writes the movie to w as text compressed with gzip. The text has the header and then a line per frame with the keys held down in hexadecimal:
+//
+//	chip8-movie 3
+//	rom <SHA-1 of the ROM>
+//	profile vip
+//	ipf 8
+//	random prng 1700000000000000000
+//	0000
+//	0012
+//
+//With the random sources which read their bytes from a file, the sequence and the vip ones, a line "data A3 07 FF" with them follows the one of the random source.
+func (m *Movie) Write(w io.Writer) error {
+	z := gzip.NewWriter(w)
+	b := bufio.NewWriter(z)
+	fmt.Fprintf(b, "%s %d\nrom %s\nprofile %s\nipf %d\nrandom %s %d\n", Magic, Version, m.ROM, m.Profile, m.IPF, m.Random, m.Seed)
+	if len(m.Data) > 0 {
+		fmt.Fprintf(b, "data % X\n", m.Data)
+	}
+	for _, keys := range m.Frames {
+		fmt.Fprintf(b, "%04X\n", keys)
+	}
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+//Create writes the movie to the file filename
+func (m *Movie) Create(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = m.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//Read reads a movie written by Write
+func Read(r io.Reader) (*Movie, error) {
+	z, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.New("not a movie")
+	}
+	defer z.Close()
+	m := &Movie{}
+	scanner := bufio.NewScanner(z)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if line == 1 {
+			if fields[0] != Magic || len(fields) != 2 || fields[1] != strconv.Itoa(Version) {
+				return nil, errors.New("not a movie, or an unsupported version")
+			}
+			continue
+		}
+		if err = m.parse(fields); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m.IPF <= 0 {
+		return nil, errors.New("the movie doesn't have the instructions per frame")
+	}
+	return m, nil
+}
+
+//Open reads the movie of the file filename
+func Open(filename string) (*Movie, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+//parse adds a line of a movie to it
+func (m *Movie) parse(fields []string) error {
+	var err error
+	value := strings.Join(fields[1:], "")
+	switch fields[0] {
+	case "rom":
+		m.ROM = value
+	case "profile":
+		m.Profile = value
+	case "ipf":
+		m.IPF, err = strconv.Atoi(value)
+	case "random":
+		if len(fields) != 3 {
+			return errors.New("expected 'random <source> <seed>'")
+		}
+		m.Random = fields[1]
+		m.Seed, err = strconv.ParseInt(fields[2], 10, 64)
+	case "data":
+		m.Data, err = chip8.ParseSequence(strings.NewReader(strings.Join(fields[1:], " ")))
+	default:
+		var keys uint64
+		if keys, err = strconv.ParseUint(fields[0], 16, 16); err != nil || len(fields) != 1 {
+			return fmt.Errorf("unknown line '%s'", strings.Join(fields, " "))
+		}
+		m.Frames = append(m.Frames, uint16(keys))
+	}
+	return err
+}
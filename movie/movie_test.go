@@ -0,0 +1,83 @@
+package movie
+
+import (
+	"bytes"
+	"github.com/NoetherianRing/Chip-8/asm"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/headless"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestMovie_Events(t *testing.T) {
+	m := &Movie{IPF: 10, Frames: []uint16{0, 1 << 5, 1<<5 | 1<<0xA, 1 << 0xA, 1 << 0xA, 0}}
+	assert.Equal(t, []headless.KeyEvent{
+		{Cycle: 10, Key: 5, Down: true},
+		{Cycle: 20, Key: 0xA, Down: true},
+		{Cycle: 30, Key: 5, Down: false},
+		{Cycle: 50, Key: 0xA, Down: false},
+	}, m.Events(), "")
+	assert.Equal(t, uint64(60), m.Cycles(), "")
+}
+
+func TestMovie_WriteRead(t *testing.T) {
+	m := &Movie{ROM: "0a1b", Profile: "schip", IPF: 30, Random: "vip", Seed: -42, Data: []byte{0x10, 0x20}, Frames: []uint16{0, 0xFFFF, 0x8001}}
+	var b bytes.Buffer
+	assert.NoError(t, m.Write(&b), "")
+	read, err := Read(bytes.NewReader(b.Bytes()))
+	assert.NoError(t, err, "")
+	assert.Equal(t, m, read, "")
+
+	_, err = Read(bytes.NewReader([]byte("chip8-movie 3\n")))
+	assert.Error(t, err, "not compressed")
+
+	c8, err := chip8.NewChip8(nil, chip8.Quirks{}, chip8.WithRandom(chip8.Sequence{0xA3, 0x07}, 1))
+	assert.NoError(t, err, "")
+	m = New(c8, "0a1b", "vip", "sequence", 10)
+	assert.Equal(t, []byte{0xA3, 0x07}, m.Data, "the bytes of the sequence are recorded")
+	b.Reset()
+	assert.NoError(t, m.Write(&b), "")
+	read, err = Read(&b)
+	assert.NoError(t, err, "")
+	assert.Equal(t, m, read, "")
+	_, err = read.RandomOption()
+	assert.NoError(t, err, "")
+}
+
+//TestMovie_Play plays a movie of a program which draws random numbers after every key, and checks that it's reproduced with its seed
+func TestMovie_Play(t *testing.T) {
+	program, err := asm.AssembleSource("random.asm", `
+loop:   LD V0, K
+        RND V1, #FF
+        RND V2, #FF
+        LD F, V0
+        DRW V1, V2, 5
+        JP loop
+`)
+	assert.NoError(t, err, "")
+	rom := filepath.Join(t.TempDir(), "random.ch8")
+	assert.NoError(t, ioutil.WriteFile(rom, program.ROM, 0644), "")
+
+	m := &Movie{Profile: "vip", IPF: 10, Random: "prng", Seed: 1234}
+	for k := 0; k < 200; k++ {
+		m.Frames = append(m.Frames, uint16(k/20%2)<<(k/40))
+	}
+	play := func(seed int64) *chip8.Snapshot {
+		quirks, err := chip8.QuirksProfile(m.Profile)
+		assert.NoError(t, err, "")
+		random, err := chip8.ParseRandom(m.Random, strconv.FormatInt(seed, 10), "")
+		assert.NoError(t, err, "")
+		r, err := headless.NewRunner(quirks, m.Events(), random)
+		assert.NoError(t, err, "")
+		r.IPF = m.IPF
+		assert.NoError(t, r.Chip8().LoadROM(rom), "")
+		assert.NoError(t, r.Run(m.Cycles()), "")
+		return r.Chip8().Snapshot()
+	}
+	first := play(m.Seed)
+	assert.Equal(t, first, play(m.Seed), "")
+	assert.NotEqual(t, first.FrameBuffer, play(m.Seed+1).FrameBuffer, "the random numbers are drawn")
+}
@@ -0,0 +1,134 @@
+package recorder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"io"
+)
+
+//pngSignature starts every PNG file
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+//apngWriter writes the chunks of an animated PNG
+type apngWriter struct {
+	w        io.Writer
+	sequence uint32 //Sequence number of the next fcTL or fdAT chunk
+	err      error
+}
+
+//chunk writes a chunk with its length and its CRC
+func (a *apngWriter) chunk(name string, data ...[]byte) {
+	if a.err != nil {
+		return
+	}
+	length := 0
+	for _, d := range data {
+		length += len(d)
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(length))
+	copy(header[4:], name)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	for _, d := range data {
+		crc.Write(d)
+	}
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	if _, a.err = a.w.Write(header[:]); a.err != nil {
+		return
+	}
+	for _, d := range data {
+		if _, a.err = a.w.Write(d); a.err != nil {
+			return
+		}
+	}
+	_, a.err = a.w.Write(footer[:])
+}
+
+//next returns the next sequence number, big endian
+func (a *apngWriter) next() []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], a.sequence)
+	a.sequence++
+	return b[:]
+}
+
+//encodeAPNG writes the recording as an animated PNG with the palette of the recorder and 8 bits per pixel.
+//The first frame is also the default image, so the viewers which don't support APNG show it.
+func (r *Recorder) encodeAPNG() error {
+	a := &apngWriter{w: r.w}
+	if _, err := io.WriteString(r.w, pngSignature); err != nil {
+		return err
+	}
+	first := r.image(r.frames[0])
+	width, height := uint32(first.Rect.Dx()), uint32(first.Rect.Dy())
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 3 //8 bits per pixel, indexed colours
+	a.chunk("IHDR", ihdr)
+
+	plte := make([]byte, 0, 3*len(r.palette))
+	for _, c := range r.palette {
+		red, green, blue, _ := c.RGBA()
+		plte = append(plte, byte(red>>8), byte(green>>8), byte(blue>>8))
+	}
+	a.chunk("PLTE", plte)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(r.frames)))
+	a.chunk("acTL", actl) //The number of plays is 0, so it loops forever
+
+	for k, f := range r.frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[4:], width)
+		binary.BigEndian.PutUint32(fctl[8:], height)
+		num, den := f.duration, FramesPerSecond
+		if num > 0xFFFF {
+			num, den = num/FramesPerSecond, 1
+		}
+		binary.BigEndian.PutUint16(fctl[20:], uint16(num))
+		binary.BigEndian.PutUint16(fctl[22:], uint16(den))
+		a.chunk("fcTL", a.next(), fctl[4:])
+
+		img := first
+		if k > 0 {
+			img = r.image(f)
+		}
+		data, err := compress(img)
+		if err != nil {
+			return err
+		}
+		if k == 0 {
+			a.chunk("IDAT", data)
+		} else {
+			a.chunk("fdAT", a.next(), data)
+		}
+	}
+	a.chunk("IEND")
+	return a.err
+}
+
+//compress returns the zlib stream of the rows of the image, each of them preceded by the filter type 0 (none)
+func compress(img *image.Paletted) ([]byte, error) {
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		if _, err := z.Write([]byte{0}); err != nil {
+			return nil, err
+		}
+		if _, err := z.Write(img.Pix[y*img.Stride : y*img.Stride+img.Rect.Dx()]); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
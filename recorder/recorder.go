@@ -0,0 +1,194 @@
+package recorder
+
+import (
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//DefaultScale is the side in pixels of a pixel of the 64x32 display in the captures, when the configuration doesn't give one
+const DefaultScale = 4
+
+//FramesPerSecond is the number of frames of the chip8 recorded every second
+const FramesPerSecond = int(time.Second / chip8.RefreshRate)
+
+//Format is the format of a recording
+type Format int
+
+const (
+	GIF  Format = iota //Animated GIF, whose frames last multiples of 1/100 of a second
+	APNG               //Animated PNG, whose frames last exactly what they lasted in the chip8
+)
+
+//ParseFormat parses the name of a format, "gif" or "apng"
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "gif":
+		return GIF, nil
+	case "apng":
+		return APNG, nil
+	}
+	return GIF, fmt.Errorf("unknown recording format '%s', expected gif or apng", s)
+}
+
+//FormatOf returns the format of a recording file given by its extension: .gif, or .png or .apng
+func FormatOf(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gif":
+		return GIF, nil
+	case ".png", ".apng":
+		return APNG, nil
+	}
+	return GIF, fmt.Errorf("'%s' isn't a recording file, expected a .gif, .png or .apng file", filename)
+}
+
+//Extension returns the extension of the files of the format
+func (f Format) Extension() string {
+	if f == APNG {
+		return ".png"
+	}
+	return ".gif"
+}
+
+//frame is a frame of a recording, which lasts a number of frames of the chip8 while the display doesn't change
+type frame struct {
+	pixels   []byte //The value of every pixel, row by row
+	highRes  bool
+	duration int //Frames of the chip8
+}
+
+//Recorder records the frames of the display of a chip8 into an animated image.
+//The frames are kept in memory, without repeating the ones which don't change, and they're encoded when the recorder is closed.
+type Recorder struct {
+	w       io.Writer
+	format  Format
+	palette color.Palette
+	scale   int
+	frames  []frame
+}
+
+//New instantiates a Recorder which writes a recording in the format to w when it's closed.
+//The pixels of the 64x32 display are squares with a side of scale pixels, and the ones of the 128x64 display are half of them.
+func New(w io.Writer, format Format, palette monitor.Palette, scale int) *Recorder {
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+	return &Recorder{w: w, format: format, palette: colors(palette), scale: scale}
+}
+
+//Create creates the file filename, and instantiates a Recorder which writes to it in the format of its extension
+func Create(filename string, palette monitor.Palette, scale int) (*Recorder, error) {
+	format, err := FormatOf(filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return New(f, format, palette, scale), nil
+}
+
+//Frame adds a frame of the display to the recording. It must be called at the end of every frame of the chip8, 60 times per second.
+func (r *Recorder) Frame(fb monitor.FrameBuffer) {
+	pixels := fb.Pixels[:fb.Width()*fb.Height()]
+	if n := len(r.frames); n > 0 {
+		last := &r.frames[n-1]
+		if last.highRes == fb.HighRes && string(last.pixels) == string(pixels) {
+			last.duration++
+			return
+		}
+	}
+	r.frames = append(r.frames, frame{pixels: append([]byte(nil), pixels...), highRes: fb.HighRes, duration: 1})
+}
+
+//Duration returns how long the recording lasts
+func (r *Recorder) Duration() time.Duration {
+	frames := 0
+	for _, f := range r.frames {
+		frames += f.duration
+	}
+	return time.Duration(frames) * time.Second / time.Duration(FramesPerSecond)
+}
+
+//Close encodes the recording and writes it, and closes the writer if it's an io.Closer.
+//A recording without frames has a single blank frame.
+func (r *Recorder) Close() error {
+	if len(r.frames) == 0 {
+		var fb monitor.FrameBuffer
+		r.Frame(fb)
+	}
+	var err error
+	if r.format == APNG {
+		err = r.encodeAPNG()
+	} else {
+		err = r.encodeGIF()
+	}
+	if closer, ok := r.w.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//encodeGIF writes the recording as an animated GIF. The delays of the frames are rounded so the recording doesn't drift from the chip8.
+func (r *Recorder) encodeGIF() error {
+	anim := &gif.GIF{}
+	elapsed, delayed := 0, 0
+	for _, f := range r.frames {
+		elapsed += f.duration
+		delay := elapsed*100/FramesPerSecond - delayed
+		delayed += delay
+		anim.Image = append(anim.Image, r.image(f))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(r.w, anim)
+}
+
+//image draws a frame of the recording
+func (r *Recorder) image(f frame) *image.Paletted {
+	var fb monitor.FrameBuffer
+	fb.HighRes = f.highRes
+	copy(fb.Pixels[:], f.pixels)
+	return draw(&fb, r.palette, r.scale)
+}
+
+//Screenshot writes the display as a PNG image, with the colours of the palette and the pixels of the 64x32 display scaled like New does
+func Screenshot(w io.Writer, fb monitor.FrameBuffer, palette monitor.Palette, scale int) error {
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+	return png.Encode(w, draw(&fb, colors(palette), scale))
+}
+
+//colors returns the colours of the palette in the order of the values of the pixels
+func colors(palette monitor.Palette) color.Palette {
+	p := make(color.Palette, len(palette))
+	for k, c := range palette {
+		p[k] = c
+	}
+	return p
+}
+
+//draw draws the display into an image of 64x32 pixels of scale x scale, with a pixel of the image per value of the palette.
+//The pixels of the 128x64 display are half of them, so all the frames of a recording have the same size.
+func draw(fb *monitor.FrameBuffer, palette color.Palette, scale int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, monitor.LowResWidth*scale, monitor.LowResHeight*scale), palette)
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Pix[y*img.Stride+x] = *fb.Get(x*fb.Width()/width, y*fb.Height()/height) & monitor.AllPlanes
+		}
+	}
+	return img
+}
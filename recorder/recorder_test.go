@@ -0,0 +1,92 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"github.com/stretchr/testify/assert"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+//frames records 30 frames with the first pixel off, 45 with it on, and 15 in high resolution
+func frames(r *Recorder) {
+	var fb monitor.FrameBuffer
+	for k := 0; k < 30; k++ {
+		r.Frame(fb)
+	}
+	*fb.Get(0, 0) = 1
+	for k := 0; k < 45; k++ {
+		r.Frame(fb)
+	}
+	fb.SetHighRes(true)
+	*fb.Get(0, 0) = 2
+	for k := 0; k < 15; k++ {
+		r.Frame(fb)
+	}
+}
+
+func TestRecorder_GIF(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, GIF, monitor.DefaultPalette, 2)
+	frames(r)
+	assert.Equal(t, 3, len(r.frames), "the frames which don't change are merged")
+	assert.Equal(t, int64(1500), r.Duration().Milliseconds(), "")
+	assert.NoError(t, r.Close(), "")
+
+	anim, err := gif.DecodeAll(&out)
+	assert.NoError(t, err, "")
+	assert.Equal(t, []int{50, 75, 25}, anim.Delay, "")
+	assert.Equal(t, 128, anim.Image[0].Rect.Dx(), "")
+	assert.Equal(t, 64, anim.Image[2].Rect.Dy(), "the high resolution frames have the same size")
+	assert.Equal(t, uint8(0), anim.Image[0].ColorIndexAt(0, 0), "")
+	assert.Equal(t, uint8(1), anim.Image[1].ColorIndexAt(1, 1), "a pixel of the 64x32 display is 2x2")
+	assert.Equal(t, uint8(0), anim.Image[1].ColorIndexAt(2, 0), "")
+	assert.Equal(t, uint8(2), anim.Image[2].ColorIndexAt(0, 0), "a pixel of the 128x64 display is 1x1")
+	assert.Equal(t, uint8(0), anim.Image[2].ColorIndexAt(1, 0), "")
+}
+
+func TestRecorder_APNG(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, APNG, monitor.DefaultPalette, 1)
+	frames(r)
+	assert.NoError(t, r.Close(), "")
+
+	img, err := png.Decode(bytes.NewReader(out.Bytes()))
+	assert.NoError(t, err, "the first frame is the default image")
+	assert.Equal(t, 64, img.Bounds().Dx(), "")
+
+	chunks := map[string]int{}
+	var delays []uint16
+	data := out.Bytes()[len(pngSignature):]
+	for len(data) > 0 {
+		length := binary.BigEndian.Uint32(data)
+		name := string(data[4:8])
+		chunks[name]++
+		if name == "fcTL" {
+			delays = append(delays, binary.BigEndian.Uint16(data[8+20:]), binary.BigEndian.Uint16(data[8+22:]))
+		}
+		data = data[12+length:]
+	}
+	assert.Equal(t, map[string]int{"IHDR": 1, "PLTE": 1, "acTL": 1, "fcTL": 3, "IDAT": 1, "fdAT": 2, "IEND": 1}, chunks, "")
+	assert.Equal(t, []uint16{30, 60, 45, 60, 15, 60}, delays, "the frames last what they lasted in the chip8")
+}
+
+func TestScreenshot(t *testing.T) {
+	var fb monitor.FrameBuffer
+	*fb.Get(63, 31) = 1
+	var out bytes.Buffer
+	assert.NoError(t, Screenshot(&out, fb, monitor.DefaultPalette, 3), "")
+	img, err := png.Decode(&out)
+	assert.NoError(t, err, "")
+	assert.Equal(t, 192, img.Bounds().Dx(), "")
+	assert.Equal(t, monitor.DefaultPalette[1], img.At(191, 95), "")
+	assert.Equal(t, monitor.DefaultPalette[0], img.At(188, 95), "")
+
+	_, err = FormatOf("capture.jpg")
+	assert.Error(t, err, "")
+	format, err := FormatOf("capture.apng")
+	assert.NoError(t, err, "")
+	assert.Equal(t, APNG, format, "")
+}
@@ -0,0 +1,97 @@
+package rewind
+
+import (
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"time"
+)
+
+//FramesPerSecond is the number of snapshots taken per second of emulation, one per frame
+const FramesPerSecond = int(time.Second / chip8.RefreshRate)
+
+//DefaultSeconds is the rewind depth when the configuration doesn't give one
+const DefaultSeconds = 10
+
+//Buffer is a ring buffer with the snapshots of the last frames of a chip8, which are popped from the newest to go back in time.
+//Only the memory of the newest snapshot is kept whole: every snapshot keeps the bytes of the memory of the previous one
+//that differ from its own, which are few because programs rarely write to memory.
+type Buffer struct {
+	frames []frame
+	start  int                      //Index of the oldest frame
+	n      int                      //Number of frames in the buffer
+	memory [chip8.AddressSpace]byte //Memory of the newest frame
+}
+
+type frame struct {
+	machine chip8.Machine
+	random  chip8.Random //State of the random source, so CXKK draws the same numbers again after going back
+	delta   []change     //Bytes of the memory of the previous frame that differ from this frame
+}
+
+type change struct {
+	address uint16
+	value   byte
+}
+
+//New instantiates a Buffer with room for the given seconds of emulation. If seconds isn't positive, DefaultSeconds are used.
+func New(seconds int) *Buffer {
+	if seconds <= 0 {
+		seconds = DefaultSeconds
+	}
+	return &Buffer{frames: make([]frame, seconds*FramesPerSecond)}
+}
+
+//Len returns the number of snapshots in the buffer
+func (b *Buffer) Len() int {
+	return b.n
+}
+
+//Cap returns the maximum number of snapshots in the buffer
+func (b *Buffer) Cap() int {
+	return len(b.frames)
+}
+
+//Reset removes all the snapshots
+func (b *Buffer) Reset() {
+	for k := range b.frames {
+		b.frames[k] = frame{}
+	}
+	b.start, b.n = 0, 0
+}
+
+//Push adds a snapshot as the newest one. When the buffer is full, the oldest snapshot is dropped.
+func (b *Buffer) Push(s *chip8.Snapshot) {
+	f := frame{machine: s.Machine, random: s.Random}
+	if b.n > 0 {
+		for address := range b.memory {
+			if b.memory[address] != s.Memory[address] {
+				f.delta = append(f.delta, change{uint16(address), b.memory[address]})
+			}
+		}
+	}
+	b.memory = s.Memory
+
+	if b.n == len(b.frames) {
+		b.frames[b.start] = frame{}
+		b.start = (b.start + 1) % len(b.frames)
+		b.n--
+	}
+	b.frames[(b.start+b.n)%len(b.frames)] = f
+	b.n++
+}
+
+//Pop removes the newest snapshot and copies it into s. It returns false, leaving s unchanged, if the buffer is empty.
+func (b *Buffer) Pop(s *chip8.Snapshot) bool {
+	if b.n == 0 {
+		return false
+	}
+	b.n--
+	f := &b.frames[(b.start+b.n)%len(b.frames)]
+	s.Memory = b.memory
+	s.Machine = f.machine
+	s.Random = f.random
+	for _, c := range f.delta {
+		b.memory[c.address] = c.value
+	}
+	*f = frame{}
+	return true
+}
@@ -0,0 +1,100 @@
+package rewind
+
+import (
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+//snapshot returns a snapshot whose memory at the address 0x300 and PC are n
+func snapshot(n int) *chip8.Snapshot {
+	s := new(chip8.Snapshot)
+	s.Memory[0x300] = byte(n)
+	s.Memory[0xFFFF] = 0xAA
+	s.PC = uint16(n)
+	s.RandomState = uint64(n)
+	return s
+}
+
+func TestBuffer_PushPop(t *testing.T) {
+	b := New(1)
+	assert.Equal(t, FramesPerSecond, b.Cap(), "")
+	for n := 0; n < 10; n++ {
+		b.Push(snapshot(n))
+	}
+	assert.Equal(t, 10, b.Len(), "")
+
+	s := new(chip8.Snapshot)
+	for n := 9; n >= 0; n-- {
+		assert.True(t, b.Pop(s), "")
+		assert.Equal(t, snapshot(n), s, "snapshot %d", n)
+	}
+	assert.False(t, b.Pop(s), "empty")
+	assert.Equal(t, snapshot(0), s, "unchanged when empty")
+}
+
+func TestBuffer_DropsOldest(t *testing.T) {
+	b := New(1)
+	frames := b.Cap() + 25
+	for n := 0; n < frames; n++ {
+		b.Push(snapshot(n))
+	}
+	assert.Equal(t, b.Cap(), b.Len(), "")
+
+	s := new(chip8.Snapshot)
+	oldest := 0
+	for b.Pop(s) {
+		oldest = int(s.PC)
+	}
+	assert.Equal(t, frames-b.Cap(), oldest, "")
+	assert.Equal(t, byte(frames-b.Cap()), s.Memory[0x300], "")
+}
+
+func TestBuffer_PushAfterPop(t *testing.T) {
+	b := New(1)
+	for n := 0; n < 5; n++ {
+		b.Push(snapshot(n))
+	}
+	s := new(chip8.Snapshot)
+	b.Pop(s)
+	b.Pop(s)
+	b.Push(snapshot(7))
+	assert.True(t, b.Pop(s), "")
+	assert.Equal(t, snapshot(7), s, "")
+	assert.True(t, b.Pop(s), "")
+	assert.Equal(t, snapshot(2), s, "back to the snapshot before the rewind")
+
+	b.Reset()
+	assert.Equal(t, 0, b.Len(), "")
+}
+
+//TestBuffer_Random rewinds a program which draws random numbers, and checks that it draws the same numbers again
+func TestBuffer_Random(t *testing.T) {
+	c8, err := chip8.NewChip8(&chip8.KeypadState{}, chip8.Quirks{}, chip8.WithRandom(chip8.PRNG{}, 99))
+	assert.NoError(t, err, "")
+	for address, value := range []byte{0xC0, 0xFF, 0x12, 0x00} { //RND V0, #FF; JP #200
+		c8.WriteMemory(uint16(0x200+address), value)
+	}
+	//draw executes a frame of 10 cycles and returns the random numbers drawn in it
+	draw := func() []byte {
+		var numbers []byte
+		for k := 0; k < 10; k++ {
+			assert.NoError(t, c8.Cycle(), "")
+			numbers = append(numbers, c8.Registers().V[0])
+		}
+		return numbers
+	}
+
+	b := New(1)
+	var frames [][]byte
+	for n := 0; n < 5; n++ {
+		b.Push(c8.Snapshot())
+		frames = append(frames, draw())
+	}
+	s := new(chip8.Snapshot)
+	b.Pop(s)
+	b.Pop(s)
+	c8.Restore(s)
+	assert.Equal(t, frames[3], draw(), "the numbers drawn after the snapshot")
+	assert.Equal(t, frames[4], draw(), "")
+}
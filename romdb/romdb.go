@@ -0,0 +1,117 @@
+package romdb
+
+import (
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+//Entry is what is known about a ROM: its title and the settings it needs to run as intended.
+//It follows the spirit of the entries of the community chip-8-database. The settings an entry doesn't have are left as they are.
+type Entry struct {
+	Title    string            `json:"title" yaml:"title"`
+	Platform string            `json:"platform" yaml:"platform"` //The platform the ROM was written for, such as originalChip8, superchip or xochip
+	Quirks   string            `json:"quirks" yaml:"quirks"`     //The quirks profile, by default the one of the platform
+	Tickrate int               `json:"tickrate" yaml:"tickrate"` //Instructions executed per frame
+	Colors   []string          `json:"colors" yaml:"colors"`     //Colours of the palette, such as #FF8800: the background, the first plane, the second plane and both planes
+	Keys     map[string]string `json:"keys" yaml:"keys"`         //Keys of the keyboard mapped to keys of the keypad, such as Up: "5"
+}
+
+//platformProfiles are the quirks profiles of the platforms, with the names of the chip-8-database and the names used by this emulator
+var platformProfiles = map[string]string{
+	"originalchip8": "vip",
+	"chip8":         "vip",
+	"vip":           "vip",
+	"chip48":        "chip48",
+	"superchip":     "schip",
+	"superchip1":    "schip",
+	"schip":         "schip",
+	"xochip":        "xochip",
+}
+
+//Profile returns the quirks profile of the entry: its quirks, or the profile of its platform if it doesn't have them.
+//It returns an empty string if the entry has neither of them, or its platform is unknown.
+func (e Entry) Profile() string {
+	if e.Quirks != "" {
+		return e.Quirks
+	}
+	return platformProfiles[strings.ToLower(strings.ReplaceAll(e.Platform, "-", ""))]
+}
+
+//Database has the entries of the ROMs, keyed by the SHA-1 hash of their bytes in lower case hexadecimal
+type Database map[string]Entry
+
+//builtin is the database of the ROMs of the assets folder, embedded in the binary
+//go:embed roms.yml
+var builtin []byte
+
+//Hash returns the key of a ROM in a Database
+func Hash(rom []byte) string {
+	sum := sha1.Sum(rom)
+	return hex.EncodeToString(sum[:])
+}
+
+//Builtin returns the built-in database
+func Builtin() Database {
+	db, err := Parse(builtin, false)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+//Open returns the built-in database with the entries of the database file filename added to it, replacing the built-in ones of the same ROMs.
+//If filename is empty, it returns the built-in database.
+func Open(filename string) (Database, error) {
+	db := Builtin()
+	if filename == "" {
+		return db, nil
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := Parse(data, strings.EqualFold(filepath.Ext(filename), ".json"))
+	if err != nil {
+		return nil, err
+	}
+	db.Add(entries)
+	return db, nil
+}
+
+//Parse parses a database written in YAML, or in JSON if isJSON is true
+func Parse(data []byte, isJSON bool) (Database, error) {
+	var entries map[string]Entry
+	var err error
+	if isJSON {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		err = yaml.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return nil, err
+	}
+	db := make(Database, len(entries))
+	for hash, entry := range entries {
+		db[strings.ToLower(hash)] = entry
+	}
+	return db, nil
+}
+
+//Add adds the entries of other to the database, replacing the ones of the same ROMs
+func (db Database) Add(other Database) {
+	for hash, entry := range other {
+		db[hash] = entry
+	}
+}
+
+//Lookup returns the entry of the ROM, and whether the database has it
+func (db Database) Lookup(rom []byte) (Entry, bool) {
+	entry, ok := db[Hash(rom)]
+	return entry, ok
+}
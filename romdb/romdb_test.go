@@ -0,0 +1,54 @@
+package romdb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltin(t *testing.T) {
+	rom, err := ioutil.ReadFile("../assets/PONG.ch8")
+	assert.NoError(t, err, "")
+	entry, ok := Builtin().Lookup(rom)
+	assert.True(t, ok, "the ROMs of the assets are in the built-in database")
+	assert.Equal(t, "Pong", entry.Title, "")
+	assert.Equal(t, "vip", entry.Profile(), "the profile of the platform")
+	assert.Equal(t, "C", entry.Keys["Up"], "")
+
+	_, ok = Builtin().Lookup([]byte{0x12, 0x00})
+	assert.False(t, ok, "")
+}
+
+func TestOpen(t *testing.T) {
+	rom := []byte{0x12, 0x00}
+	dir := t.TempDir()
+	files := map[string]string{
+		"roms.json": `{"` + Hash(rom) + `": {"title": "Loop", "platform": "superchip", "tickrate": 30, "colors": ["#000000", "#FF8800"]}}`,
+		"roms.yml":  "\"" + Hash(rom) + "\":\n  title: \"Loop\"\n  platform: \"superchip\"\n  quirks: \"chip48\"\n",
+	}
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(filename, []byte(content), 0644), "")
+		db, err := Open(filename)
+		assert.NoError(t, err, name)
+		entry, ok := db.Lookup(rom)
+		assert.True(t, ok, name)
+		assert.Equal(t, "Loop", entry.Title, name)
+		assert.Equal(t, len(Builtin())+1, len(db), "the entries are added to the built-in ones")
+	}
+
+	db, err := Open(filepath.Join(dir, "roms.json"))
+	assert.NoError(t, err, "")
+	entry, _ := db.Lookup(rom)
+	assert.Equal(t, "schip", entry.Profile(), "")
+	assert.Equal(t, 30, entry.Tickrate, "")
+	assert.Equal(t, []string{"#000000", "#FF8800"}, entry.Colors, "")
+	db, err = Open(filepath.Join(dir, "roms.yml"))
+	assert.NoError(t, err, "")
+	entry, _ = db.Lookup(rom)
+	assert.Equal(t, "chip48", entry.Profile(), "the quirks replace the profile of the platform")
+
+	_, err = Open(filepath.Join(dir, "missing.yml"))
+	assert.Error(t, err, "")
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/config"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"github.com/NoetherianRing/Chip-8/romdb"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+//defaultConfig is the configuration file read when --config isn't given. If it doesn't exist, the built-in defaults are used.
+const defaultConfig = "config.yml"
+
+//settings are the flags shared by the commands which run a ROM. They are layered over the configuration:
+//only the flags given in the command line replace the settings of the configuration file.
+type settings struct {
+	flags      *flag.FlagSet
+	config     string
+	romdb      string
+	font       string
+	quirks     string
+	palette    string
+	random     string
+	seed       string
+	randomFile string
+	speed      int
+	scale      int
+	debug      bool
+}
+
+//addSettings defines the flags of the settings in flags
+func addSettings(flags *flag.FlagSet) *settings {
+	s := addConfigFiles(flags)
+	flags.StringVar(&s.font, "font", "", "the font file")
+	flags.StringVar(&s.quirks, "quirks", "", "the quirks profile: "+strings.Join(chip8.QuirksProfiles(), ", "))
+	flags.IntVar(&s.speed, "speed", 0, "the instructions executed per frame, 60 frames per second")
+	flags.IntVar(&s.scale, "scale", 0, "the side in pixels of a pixel of the 64x32 display")
+	flags.StringVar(&s.random, "random", "", "the generator of the random numbers: prng, vip or sequence")
+	flags.StringVar(&s.seed, "seed", "", "the seed of the random numbers, a number or clock")
+	flags.StringVar(&s.randomFile, "random-file", "", "the file of the hexadecimal bytes of the sequence generator, or the dump of the interpreter of the vip one")
+	flags.StringVar(&s.palette, "palette", "", "the colours of the display, replacing the ones of the configuration: "+strings.Join(monitor.PresetPalettes(), ", "))
+	flags.BoolVar(&s.debug, "debug", false, "run the ROM under the debugger")
+	return s
+}
+
+//addConfigFiles defines in flags only the flags of the settings which select the configuration file and the ROM database
+func addConfigFiles(flags *flag.FlagSet) *settings {
+	s := &settings{flags: flags}
+	flags.StringVar(&s.config, "config", defaultConfig, "the configuration file, the built-in defaults are used if "+defaultConfig+" doesn't exist")
+	flags.StringVar(&s.romdb, "romdb", "", "a ROM database file, its entries are added to the built-in ones")
+	return s
+}
+
+//load reads the configuration file and applies the flags given over it.
+//A missing configuration file is only an error if it was given with --config.
+func (s *settings) load() (config.Config, error) {
+	cfg, err := s.read()
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, s.apply(&cfg)
+}
+
+//loadROM loads the configuration like load to run the ROM rom, or the ROM of the configuration if rom is empty.
+//If the ROM is in the ROM database, the settings of its entry are applied over the configuration file, and the flags given over them.
+func (s *settings) loadROM(rom string) (config.Config, error) {
+	cfg, err := s.read()
+	if err != nil {
+		return cfg, err
+	}
+	if rom != "" {
+		cfg.Paths.Rom = rom
+	}
+	if cfg.Paths.Rom != "" {
+		entry, ok, err := lookup(cfg)
+		if err != nil {
+			return cfg, err
+		}
+		if ok {
+			applyEntry(&cfg, entry)
+		}
+	}
+	return cfg, s.apply(&cfg)
+}
+
+//given returns the names of the flags given in the command line
+func (s *settings) given() map[string]bool {
+	given := map[string]bool{}
+	s.flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	return given
+}
+
+//read reads the configuration file, with the ROM database given by the flags
+func (s *settings) read() (config.Config, error) {
+	given := s.given()
+	cfg, err := config.Load(s.config)
+	if err != nil && (given["config"] || !errors.Is(err, os.ErrNotExist)) {
+		return cfg, err
+	}
+	if given["romdb"] {
+		cfg.Paths.ROMDB = s.romdb
+	}
+	return cfg, nil
+}
+
+//apply applies the flags given in the command line over the configuration
+func (s *settings) apply(cfg *config.Config) error {
+	given := s.given()
+	if given["font"] {
+		cfg.Paths.Fonts = s.font
+	}
+	if given["quirks"] {
+		if _, err := chip8.QuirksProfile(s.quirks); err != nil {
+			return err
+		}
+		cfg.Quirks.Profile = s.quirks
+	}
+	if given["speed"] {
+		if s.speed < 1 {
+			return errors.New("the speed must be at least 1 instruction per frame")
+		}
+		cfg.Speed.IPF = s.speed
+	}
+	if given["random"] {
+		cfg.Random.Source = s.random
+	}
+	if given["seed"] {
+		cfg.Random.Seed = s.seed
+	}
+	if given["random-file"] {
+		cfg.Random.File = s.randomFile
+	}
+	if _, err := random(*cfg); err != nil {
+		return err
+	}
+	if given["scale"] {
+		if s.scale < 1 {
+			return errors.New("the scale must be at least 1")
+		}
+		cfg.Display.Scale = s.scale
+	}
+	if given["palette"] {
+		if _, err := monitor.PresetPalette(s.palette); err != nil {
+			return err
+		}
+		cfg.Display.Palette, cfg.Display.Colors = s.palette, nil
+	}
+	if given["debug"] {
+		cfg.Debug.On = "false"
+		if s.debug {
+			cfg.Debug.On = "true"
+		}
+	}
+	return nil
+}
+
+//quirks returns the quirks of the profile of the configuration, no quirks if it doesn't have a profile
+func quirks(cfg config.Config) (chip8.Quirks, error) {
+	if cfg.Quirks.Profile == "" {
+		return chip8.Quirks{}, nil
+	}
+	return chip8.QuirksProfile(cfg.Quirks.Profile)
+}
+
+//random returns the option of the random source and the seed of the configuration
+func random(cfg config.Config) (chip8.Option, error) {
+	return chip8.ParseRandom(cfg.Random.Source, cfg.Random.Seed, cfg.Random.File)
+}
+
+//loadFonts loads the font file of the configuration into the chip8, which keeps its built-in fonts if there isn't one
+func loadFonts(c8 *chip8.Chip8, cfg config.Config) error {
+	if cfg.Paths.Fonts == "" {
+		return nil
+	}
+	return c8.LoadFonts(cfg.Paths.Fonts)
+}
+
+//lookup returns the entry of the ROM of the configuration in the ROM database, and whether the database has it
+func lookup(cfg config.Config) (romdb.Entry, bool, error) {
+	db, err := romdb.Open(cfg.Paths.ROMDB)
+	if err != nil {
+		return romdb.Entry{}, false, err
+	}
+	rom, err := ioutil.ReadFile(cfg.Paths.Rom)
+	if err != nil {
+		return romdb.Entry{}, false, err
+	}
+	entry, ok := db.Lookup(rom)
+	return entry, ok, nil
+}
+
+//applyEntry applies the settings of an entry of the ROM database over the configuration.
+//Its keys are added to the ones of the configuration.
+func applyEntry(cfg *config.Config, entry romdb.Entry) {
+	if profile := entry.Profile(); profile != "" {
+		cfg.Quirks.Profile = profile
+	}
+	if entry.Tickrate > 0 {
+		cfg.Speed.IPF = entry.Tickrate
+	}
+	if len(entry.Colors) > 0 {
+		cfg.Display.Colors = entry.Colors
+	}
+	if len(entry.Keys) > 0 {
+		keys := map[string]string{}
+		for name, key := range cfg.Keys {
+			keys[name] = key
+		}
+		for name, key := range entry.Keys {
+			keys[name] = key
+		}
+		cfg.Keys = keys
+	}
+}
@@ -19,4 +19,5 @@ type StateChip8 struct {
 	SoundTimer  byte
 	MustDraw    bool
 	Quit        bool
+	Seed        int64 //Seed of the random numbers of CXKK, a run with the same seed and the same inputs draws the same numbers
 }
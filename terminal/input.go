@@ -0,0 +1,130 @@
+package terminal
+
+import (
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//KeyHold is how long a key of the keypad is held down after its key is read from the terminal.
+//Terminals don't report when a key is released, so a key stays down while the terminal repeats it.
+const KeyHold = 150 * time.Millisecond
+
+//Sequences read from the terminal which aren't keys of the keypad
+const (
+	keyEscape = "\x1b"
+	keyCtrlC  = "\x03"
+)
+
+//KeyMap maps the sequences read from the terminal when a key is pressed to keys of the keypad.
+//The letters are in lower case.
+type KeyMap map[string]byte
+
+//DefaultKeyMap has the layout of keyhandlers.KeyboardToKeypad:
+//	 |1|2|3|4|        |1|2|3|C|
+//	|Q|W|E|R|         |4|5|6|D|
+//	|A|S|D|F|         |7|8|9|E|
+//	|Z|X|C|V|         |A|0|B|F|
+var DefaultKeyMap = KeyMap{
+	"1": 1, "2": 2, "3": 3, "4": 0xC,
+	"q": 4, "w": 5, "e": 6, "r": 0xD,
+	"a": 7, "s": 8, "d": 9, "f": 0xE,
+	"z": 0xA, "x": 0, "c": 0xB, "v": 0xF,
+}
+
+//namedKeys are the sequences of the keys with names of pixelgl which terminals send
+var namedKeys = map[string]string{
+	"up":    "\x1b[A",
+	"down":  "\x1b[B",
+	"right": "\x1b[C",
+	"left":  "\x1b[D",
+	"space": " ",
+	"enter": "\r",
+	"tab":   "\t",
+}
+
+//ParseKeyMap returns DefaultKeyMap with the keys of keys added to it, replacing the ones it has.
+//keys are the keys of the configuration: names of keys of the keyboard, such as "Up" or "W", mapped to keys of the keypad written in hexadecimal.
+//The keys a terminal doesn't send, such as "LeftShift", are ignored.
+func ParseKeyMap(keys map[string]string) (KeyMap, error) {
+	keyMap := KeyMap{}
+	for seq, key := range DefaultKeyMap {
+		keyMap[seq] = key
+	}
+	for name, value := range keys {
+		key, err := strconv.ParseUint(value, 16, 8)
+		if err != nil || key > 0xF {
+			return nil, fmt.Errorf("'%s' is not a key of the keypad, expected a hexadecimal digit", value)
+		}
+		seq, ok := namedKeys[strings.ToLower(name)]
+		if len(name) == 1 {
+			seq, ok = strings.ToLower(name), true
+		}
+		if ok {
+			keyMap[seq] = byte(key)
+		}
+	}
+	return keyMap, nil
+}
+
+//split splits the bytes read from the terminal into the sequences of the keys pressed.
+//The escape sequences of keys such as the arrow keys are kept whole, in their ESC [ form, and the letters are turned to lower case.
+func split(data []byte) []string {
+	var seqs []string
+	for k := 0; k < len(data); {
+		n := 1
+		if data[k] == 0x1b && k+2 < len(data) && (data[k+1] == '[' || data[k+1] == 'O') {
+			n = 2
+			for k+n < len(data) && (data[k+n] < 0x40 || data[k+n] > 0x7E) {
+				n++
+			}
+			n = min(n+1, len(data)-k)
+		}
+		seq := string(data[k : k+n])
+		if n == 1 {
+			seq = strings.ToLower(seq)
+		} else if seq[1] == 'O' {
+			seq = "\x1b[" + seq[2:]
+		}
+		seqs = append(seqs, seq)
+		k += n
+	}
+	return seqs
+}
+
+//keys presses the keys of the keypad read from the terminal, and releases them KeyHold after they were read for the last time
+type keys struct {
+	keypad   *chip8.KeypadState
+	keyMap   KeyMap
+	deadline [chip8.NumberOfKeys]time.Time //When every key is released, the zero time if it's up
+}
+
+//press presses the key of seq, if it's a key of the keypad, until KeyHold after now
+func (k *keys) press(seq string, now time.Time) {
+	key, ok := k.keyMap[seq]
+	if !ok {
+		return
+	}
+	k.keypad.Press(key)
+	k.deadline[key] = now.Add(KeyHold)
+}
+
+//release releases the keys whose deadline passed
+func (k *keys) release(now time.Time) {
+	for key, deadline := range k.deadline {
+		if !deadline.IsZero() && !now.Before(deadline) {
+			k.keypad.Release(byte(key))
+			k.deadline[key] = time.Time{}
+		}
+	}
+}
+
+//min returns the smallest of a and b
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,32 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+//MakeRaw puts the terminal of f in raw mode with stty, so the keys can be read as they are pressed and they aren't echoed.
+//It returns a function which restores the previous mode of the terminal.
+func MakeRaw(f *os.File) (func() error, error) {
+	saved, err := stty(f, "-g")
+	if err != nil {
+		return nil, errors.New("the standard input isn't a terminal which stty can put in raw mode")
+	}
+	if _, err = stty(f, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := stty(f, strings.TrimSpace(saved))
+		return err
+	}, nil
+}
+
+//stty runs stty with the given arguments on the terminal of f, and returns its output
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return string(out), err
+}
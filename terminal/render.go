@@ -0,0 +1,154 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"image/color"
+	"io"
+	"strings"
+)
+
+//Mode is how the pixels of the frame buffer are drawn with the characters of the terminal
+type Mode int
+
+const (
+	HalfBlocks Mode = iota //A character per column and two rows: an upper half block in the colour of the upper pixel over the colour of the lower one
+	Braille                //A braille character per two columns and four rows, with a dot per pixel that is on
+)
+
+//ParseMode parses the name of a mode, "halfblocks" or "braille"
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(s) {
+	case "", "halfblocks":
+		return HalfBlocks, nil
+	case "braille":
+		return Braille, nil
+	}
+	return HalfBlocks, fmt.Errorf("unknown terminal mode '%s', expected halfblocks or braille", s)
+}
+
+//cellSize returns the number of columns and rows of pixels drawn by a character in the mode
+func (m Mode) cellSize() (int, int) {
+	if m == Braille {
+		return 2, 4
+	}
+	return 1, 2
+}
+
+//brailleDots are the bits of the dots of a braille character, for the pixel (x, y) of a cell of 2x4 pixels
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+//Renderer is a monitor.Monitor which draws the frame buffer of the chip8 in a terminal with ANSI escape codes and 24 bit colours.
+//The screen is drawn at the upper left corner of the terminal, with an optional sidebar to the right of it and the text drawn by ToDrawText below it.
+type Renderer struct {
+	w       io.Writer
+	mode    Mode
+	palette monitor.Palette
+	sidebar func() []string //Lines drawn to the right of the screen, nil if there isn't a sidebar
+	rows    int             //Lines of the screen drawn by the last call to ToDraw
+	buf     bytes.Buffer
+}
+
+//NewRenderer instantiates a Renderer which writes to w, drawing the pixels in the mode with the colours of the palette
+func NewRenderer(w io.Writer, mode Mode, palette monitor.Palette) *Renderer {
+	return &Renderer{w: w, mode: mode, palette: palette}
+}
+
+//SetSidebar makes the renderer call sidebar every time it draws the screen, and draw the lines it returns to the right of it
+func (r *Renderer) SetSidebar(sidebar func() []string) {
+	r.sidebar = sidebar
+}
+
+//ToDraw draws the frame buffer, and the sidebar if the renderer has one, replacing what was drawn before
+func (r *Renderer) ToDraw(buffer monitor.FrameBuffer) {
+	r.buf.Reset()
+	r.buf.WriteString("\x1b[H")
+	lines := r.screen(&buffer)
+	var sidebar []string
+	if r.sidebar != nil {
+		sidebar = r.sidebar()
+	}
+	width, _ := r.mode.cellSize()
+	blank := strings.Repeat(" ", buffer.Width()/width)
+	r.rows = len(lines)
+	for k := 0; k < len(lines) || k < len(sidebar); k++ {
+		if k < len(lines) {
+			r.buf.WriteString(lines[k])
+		} else {
+			r.buf.WriteString(blank)
+		}
+		if k < len(sidebar) {
+			r.buf.WriteString("  " + sidebar[k])
+		}
+		r.buf.WriteString("\x1b[K\r\n")
+	}
+	r.buf.WriteString("\x1b[J")
+	_, _ = r.w.Write(r.buf.Bytes())
+}
+
+//ToDrawText draws a message below the screen drawn by the last call to ToDraw
+func (r *Renderer) ToDrawText(message string) {
+	r.buf.Reset()
+	fmt.Fprintf(&r.buf, "\x1b[%d;1H\x1b[J", r.rows+2)
+	r.buf.WriteString(strings.ReplaceAll(message, "\n", "\r\n"))
+	r.buf.WriteString("\r\n")
+	_, _ = r.w.Write(r.buf.Bytes())
+}
+
+//screen returns the lines of characters which draw the frame buffer, each of them ending with the attributes reset
+func (r *Renderer) screen(buffer *monitor.FrameBuffer) []string {
+	width, height := r.mode.cellSize()
+	var lines []string
+	var sb strings.Builder
+	for y := 0; y < buffer.Height(); y += height {
+		sb.Reset()
+		var fg, bg color.RGBA
+		started := false
+		for x := 0; x < buffer.Width(); x += width {
+			var char rune
+			var cellFg, cellBg color.RGBA
+			if r.mode == Braille {
+				char, cellFg, cellBg = r.braille(buffer, x, y)
+			} else {
+				char = '▀'
+				cellFg = r.palette[*buffer.Get(x, y)&monitor.AllPlanes]
+				cellBg = r.palette[*buffer.Get(x, y+1)&monitor.AllPlanes]
+			}
+			if !started || cellFg != fg {
+				fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm", cellFg.R, cellFg.G, cellFg.B)
+			}
+			if !started || cellBg != bg {
+				fmt.Fprintf(&sb, "\x1b[48;2;%d;%d;%dm", cellBg.R, cellBg.G, cellBg.B)
+			}
+			fg, bg, started = cellFg, cellBg, true
+			sb.WriteRune(char)
+		}
+		sb.WriteString("\x1b[0m")
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+//braille returns the braille character of the cell whose upper left pixel is (x, y), and its colours:
+//the dots are drawn with the colour of the highest value of the pixels of the cell, over the background
+func (r *Renderer) braille(buffer *monitor.FrameBuffer, x, y int) (rune, color.RGBA, color.RGBA) {
+	char := rune(0x2800)
+	var value byte
+	for dy := 0; dy < 4; dy++ {
+		for dx := 0; dx < 2; dx++ {
+			if pixel := *buffer.Get(x+dx, y+dy) & monitor.AllPlanes; pixel != 0 {
+				char |= brailleDots[dy][dx]
+				if pixel > value {
+					value = pixel
+				}
+			}
+		}
+	}
+	return char, r.palette[value], r.palette[0]
+}
@@ -0,0 +1,41 @@
+package terminal
+
+import (
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/disasm"
+)
+
+//SidebarInstructions is the number of instructions disassembled by Sidebar, starting at the PC
+const SidebarInstructions = 8
+
+//Sidebar returns the lines of a sidebar with the registers of the chip8, and the disassembly of the instructions at the PC
+func Sidebar(c8 *chip8.Chip8) []string {
+	regs := c8.Registers()
+	lines := []string{
+		fmt.Sprintf("PC %03X  I %03X  SP %X", regs.PC, regs.I, regs.SP),
+	}
+	for k := 0; k < chip8.NumberOfRegisters; k += 4 {
+		lines = append(lines, fmt.Sprintf("V%X %02X  V%X %02X  V%X %02X  V%X %02X", k, regs.V[k], k+1, regs.V[k+1], k+2, regs.V[k+2], k+3, regs.V[k+3]))
+	}
+	lines = append(lines, fmt.Sprintf("DT %02X  ST %02X", regs.DelayTimer, regs.SoundTimer), "")
+
+	addr := regs.PC
+	for k := 0; k < SidebarInstructions; k++ {
+		var memory [4]byte
+		for b := range memory {
+			memory[b] = c8.ReadMemory(addr + uint16(b))
+		}
+		marker := " "
+		if k == 0 {
+			marker = ">"
+		}
+		mnemonic, size := "???", 2
+		if in, ok := disasm.Decode(memory[:]); ok {
+			mnemonic, size = in.String(), in.Size
+		}
+		lines = append(lines, fmt.Sprintf("%s %03X  %02X%02X  %s", marker, addr, memory[0], memory[1], mnemonic))
+		addr += uint16(size)
+	}
+	return lines
+}
@@ -0,0 +1,103 @@
+package terminal
+
+import (
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"io"
+	"os"
+	"time"
+)
+
+//Options are the settings of a chip8 run in a terminal
+type Options struct {
+	Mode    Mode
+	Palette monitor.Palette
+	Keys    KeyMap //Keys read from the terminal, DefaultKeyMap if it's nil
+	IPF     int    //Instructions executed per frame
+	Sidebar bool   //Draw the registers and the next instructions to the right of the screen
+	Bell    bool   //Ring the bell of the terminal when the chip8 starts beeping
+}
+
+//Run runs the chip8 in a terminal frame by frame, reading the keys of the keypad from in and drawing the screen to out,
+//until the program exits or Esc or Ctrl+C is pressed. in must be a terminal, which is in raw mode while the chip8 runs.
+//If the program makes the chip8 fail, the fault is shown below the screen until Esc or Ctrl+C is pressed, and then it's returned.
+func Run(c8 *chip8.Chip8, keypad *chip8.KeypadState, in *os.File, out io.Writer, o Options) error {
+	restore, err := MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	_, _ = io.WriteString(out, "\x1b[?1049h\x1b[?25l\x1b[2J")
+	defer io.WriteString(out, "\x1b[?25h\x1b[?1049l")
+
+	renderer := NewRenderer(out, o.Mode, o.Palette)
+	if o.Sidebar {
+		renderer.SetSidebar(func() []string {
+			return Sidebar(c8)
+		})
+	}
+	if o.Keys == nil {
+		o.Keys = DefaultKeyMap
+	}
+	if o.IPF <= 0 {
+		o.IPF = chip8.DefaultIPF
+	}
+	k := keys{keypad: keypad, keyMap: o.Keys}
+	input := make(chan string, 16)
+	go read(in, input)
+
+	frame := time.NewTicker(chip8.RefreshRate)
+	defer frame.Stop()
+	var fault error
+	drawn, beeping := false, false
+	for !c8.IsClosed() {
+		select {
+		case seq, ok := <-input:
+			if !ok || seq == keyEscape || seq == keyCtrlC {
+				c8.Close()
+				continue
+			}
+			k.press(seq, time.Now())
+		case now := <-frame.C:
+			k.release(now)
+			if fault != nil {
+				continue
+			}
+			for n := 0; n < o.IPF && !c8.IsClosed(); n++ {
+				if fault = c8.Cycle(); fault != nil {
+					break
+				}
+			}
+			c8.TickTimers()
+			if fault != nil {
+				renderer.ToDraw(c8.GetFrameBuffer())
+				renderer.ToDrawText("HALTED\n" + fault.Error() + "\nPress Esc to quit")
+				continue
+			}
+			if !drawn || c8.MustDraw || o.Sidebar {
+				drawn, c8.MustDraw = true, false
+				renderer.ToDraw(c8.GetFrameBuffer())
+			}
+			if o.Bell && c8.MustBeep() && !beeping {
+				_, _ = io.WriteString(out, "\a")
+			}
+			beeping = c8.MustBeep()
+		}
+	}
+	return fault
+}
+
+//read sends the sequences of the keys read from in to input, until reading fails
+func read(in io.Reader, input chan<- string) {
+	defer close(input)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		for _, seq := range split(buf[:n]) {
+			input <- seq
+		}
+		if err != nil {
+			return
+		}
+	}
+}
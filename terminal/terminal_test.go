@@ -0,0 +1,107 @@
+package terminal
+
+import (
+	"bytes"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/monitor"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+//visible returns the characters drawn by the renderer, without the escape sequences
+func visible(s string) string {
+	var sb strings.Builder
+	for k := 0; k < len(s); k++ {
+		if s[k] != 0x1b {
+			sb.WriteByte(s[k])
+			continue
+		}
+		for k++; k < len(s) && (s[k] < 0x40 || s[k] > 0x7E || s[k] == '['); k++ {
+		}
+	}
+	return sb.String()
+}
+
+func TestRenderer_HalfBlocks(t *testing.T) {
+	var fb monitor.FrameBuffer
+	*fb.Get(0, 0) = 1
+	*fb.Get(1, 1) = 2
+	var out bytes.Buffer
+	NewRenderer(&out, HalfBlocks, monitor.DefaultPalette).ToDraw(fb)
+
+	lines := strings.Split(visible(out.String()), "\r\n")
+	assert.Equal(t, monitor.LowResHeight/2+1, len(lines), "a line per two rows and the end")
+	assert.Equal(t, strings.Repeat("▀", monitor.LowResWidth), lines[0], "")
+	assert.True(t, strings.HasPrefix(out.String(), "\x1b[H\x1b[38;2;255;255;255m\x1b[48;2;0;0;0m▀\x1b[38;2;0;0;0m\x1b[48;2;170;170;170m▀"), "the colours of the upper and the lower pixel")
+
+	fb.SetHighRes(true)
+	out.Reset()
+	NewRenderer(&out, HalfBlocks, monitor.DefaultPalette).ToDraw(fb)
+	lines = strings.Split(visible(out.String()), "\r\n")
+	assert.Equal(t, monitor.HighResHeight/2+1, len(lines), "")
+	assert.Equal(t, monitor.HighResWidth, len([]rune(lines[0])), "")
+}
+
+func TestRenderer_Braille(t *testing.T) {
+	var fb monitor.FrameBuffer
+	*fb.Get(0, 0) = 1
+	*fb.Get(1, 3) = 1
+	*fb.Get(2, 1) = 3
+	var out bytes.Buffer
+	renderer := NewRenderer(&out, Braille, monitor.DefaultPalette)
+	renderer.SetSidebar(func() []string {
+		return []string{"PC 200"}
+	})
+	renderer.ToDraw(fb)
+
+	lines := strings.Split(visible(out.String()), "\r\n")
+	assert.Equal(t, monitor.LowResHeight/4+1, len(lines), "")
+	cells := []rune(lines[0])
+	assert.Equal(t, rune(0x2800|0x01|0x80), cells[0], "the dots of (0, 0) and (1, 3)")
+	assert.Equal(t, rune(0x2800|0x02), cells[1], "")
+	assert.Equal(t, "  PC 200", string(cells[monitor.LowResWidth/2:]), "the sidebar")
+	assert.Contains(t, out.String(), "\x1b[38;2;85;85;85m⠂", "the colour of both planes")
+}
+
+func TestSidebar(t *testing.T) {
+	c8, err := chip8.NewChip8(nil, chip8.Quirks{})
+	assert.NoError(t, err, "")
+	c8.WriteMemory(0x200, 0x60)
+	c8.WriteMemory(0x201, 0x05)
+	c8.WriteMemory(0x202, 0xF0)
+	c8.WriteMemory(0x203, 0x00)
+	assert.NoError(t, c8.Cycle(), "")
+
+	lines := Sidebar(c8)
+	assert.Equal(t, "PC 202  I 000  SP 0", lines[0], "")
+	assert.Equal(t, "V0 05  V1 00  V2 00  V3 00", lines[1], "")
+	assert.Equal(t, "> 202  F000  LD I, long #0000", lines[7], "")
+	assert.Equal(t, "  206  0000  ???", lines[8], "the long instruction takes 4 bytes")
+	assert.Equal(t, 7+SidebarInstructions, len(lines), "")
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []string{"w", "\x1b[A", " ", "\x1b[B", "\x1b", "\x03"}, split([]byte("W\x1b[A \x1bOB\x1b\x03")), "")
+
+	keyMap, err := ParseKeyMap(map[string]string{"Up": "C", "W": "1", "LeftShift": "2"})
+	assert.NoError(t, err, "")
+	assert.Equal(t, byte(0xC), keyMap["\x1b[A"], "")
+	assert.Equal(t, byte(1), keyMap["w"], "")
+	assert.Equal(t, byte(4), keyMap["q"], "the default layout")
+	_, err = ParseKeyMap(map[string]string{"Up": "10"})
+	assert.Error(t, err, "")
+
+	keypad := &chip8.KeypadState{}
+	k := keys{keypad: keypad, keyMap: keyMap}
+	now := time.Now()
+	k.press("w", now)
+	k.press("y", now)
+	assert.True(t, keypad.IsPressed(1), "")
+	k.press("w", now.Add(KeyHold/2))
+	k.release(now.Add(KeyHold))
+	assert.True(t, keypad.IsPressed(1), "the key is held while it's repeated")
+	k.release(now.Add(KeyHold * 3 / 2))
+	assert.False(t, keypad.IsPressed(1), "")
+}
@@ -0,0 +1,78 @@
+package trace
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Range is a range of numbers from From to To, To excluded. If To is 0 the range doesn't have an upper bound,
+//so the zero value contains every number.
+type Range struct {
+	From uint64
+	To   uint64
+}
+
+//Contains reports if n is in the range
+func (r Range) Contains(n uint64) bool {
+	return n >= r.From && (r.To == 0 || n < r.To)
+}
+
+//Filter selects the instructions that are traced. The zero value traces every instruction.
+type Filter struct {
+	PC      Range  //Addresses of the instructions
+	Cycles  Range  //Cycles in which the instructions are executed, the first instruction is executed in the cycle 0
+	Classes uint16 //Bit n is set to trace the opcodes whose first hexadecimal digit is n, 0 traces all of them
+}
+
+//Match reports if the instruction with the given address and opcode, executed in the given cycle, must be traced
+func (f Filter) Match(pc uint16, opcode uint16, cycle uint64) bool {
+	if f.Classes != 0 && f.Classes&(1<<(opcode>>12)) == 0 {
+		return false
+	}
+	return f.PC.Contains(uint64(pc)) && f.Cycles.Contains(cycle)
+}
+
+//ParseRange parses a range with the format "from-to", both included, or "from-" without an upper bound,
+//or a single number. The numbers are written in the given base. An empty string is the range of every number.
+func ParseRange(s string, base int) (Range, error) {
+	if s == "" {
+		return Range{}, nil
+	}
+	from, to := s, s
+	if k := strings.Index(s, "-"); k >= 0 {
+		from, to = s[:k], s[k+1:]
+	}
+	var r Range
+	var err error
+	if r.From, err = strconv.ParseUint(from, base, 64); err != nil {
+		return Range{}, fmt.Errorf("'%s' is not a range", s)
+	}
+	if to == "" {
+		return r, nil
+	}
+	last, err := strconv.ParseUint(to, base, 64)
+	if err != nil || last < r.From {
+		return Range{}, fmt.Errorf("'%s' is not a range", s)
+	}
+	r.To = last + 1
+	return r, nil
+}
+
+//ParseClasses parses a list of opcode classes separated by commas, each of them the first hexadecimal digit of the opcodes,
+//such as "8,D,F". An empty string selects every class.
+func ParseClasses(s string) (uint16, error) {
+	var classes uint16
+	for _, class := range strings.Split(s, ",") {
+		class = strings.TrimSpace(class)
+		if class == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(class, 16, 4)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not an opcode class", class)
+		}
+		classes |= 1 << n
+	}
+	return classes, nil
+}
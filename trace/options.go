@@ -0,0 +1,48 @@
+package trace
+
+//DefaultFiles is the number of old trace files kept when the trace is rotated
+const DefaultFiles = 3
+
+//Options are the settings of a trace, written as in the configuration and in the flags of the run command
+type Options struct {
+	File    string //File of the trace
+	Format  string //text or ndjson
+	PC      string //Hexadecimal range of the addresses of the traced instructions, such as "200-2FF"
+	Opcodes string //Classes of the traced opcodes, such as "8,D"
+	Cycles  string //Decimal range of the cycles traced, such as "1000-2000" or "1000-"
+	MaxSize string //Size at which the file is rotated, such as "10M", the file isn't rotated if it's empty
+	Files   int    //Number of old files kept when the file is rotated, DefaultFiles if it's 0
+}
+
+//Open creates the file of the options and returns a Tracer which writes to it
+func Open(o Options) (*Tracer, error) {
+	format, err := ParseFormat(o.Format)
+	if err != nil {
+		return nil, err
+	}
+	var filter Filter
+	if filter.PC, err = ParseRange(o.PC, 16); err != nil {
+		return nil, err
+	}
+	if filter.Cycles, err = ParseRange(o.Cycles, 10); err != nil {
+		return nil, err
+	}
+	if filter.Classes, err = ParseClasses(o.Opcodes); err != nil {
+		return nil, err
+	}
+	var maxSize int64
+	if o.MaxSize != "" {
+		if maxSize, err = ParseSize(o.MaxSize); err != nil {
+			return nil, err
+		}
+	}
+	files := o.Files
+	if files == 0 {
+		files = DefaultFiles
+	}
+	f, err := NewRotatingFile(o.File, maxSize, files)
+	if err != nil {
+		return nil, err
+	}
+	return New(f, format, filter), nil
+}
@@ -0,0 +1,88 @@
+package trace
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//RotatingFile is a file which is rotated when it reaches a maximum size: name is renamed to name.1, name.1 to name.2
+//and so on, keeping a number of old files, and the writes continue in a new file name.
+//A single Write is never split between two files.
+type RotatingFile struct {
+	name    string
+	maxSize int64
+	keep    int
+	f       *os.File
+	size    int64
+}
+
+//NewRotatingFile creates the file name, which is rotated when writing to it would exceed maxSize bytes, keeping keep old files.
+//If maxSize isn't positive, the file is never rotated.
+func NewRotatingFile(name string, maxSize int64, keep int) (*RotatingFile, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &RotatingFile{name: name, maxSize: maxSize, keep: keep, f: f}, nil
+}
+
+//Write writes p to the current file, rotating it before if p doesn't fit in it
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+//Close closes the current file
+func (r *RotatingFile) Close() error {
+	return r.f.Close()
+}
+
+//rotate closes the current file, shifts the names of the old files dropping the oldest one, and creates a new file
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.keep > 0 {
+		for k := r.keep - 1; k >= 1; k-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", r.name, k), fmt.Sprintf("%s.%d", r.name, k+1))
+		}
+		if err := os.Rename(r.name, r.name+".1"); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(r.name)
+	if err != nil {
+		return err
+	}
+	r.f, r.size = f, 0
+	return nil
+}
+
+//ParseSize parses a size in bytes, which can end with K, M or G for kibibytes, mebibytes and gibibytes, such as "10M"
+func ParseSize(s string) (int64, error) {
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("'%s' is not a size", s)
+	}
+	return n * multiplier, nil
+}
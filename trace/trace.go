@@ -0,0 +1,157 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/NoetherianRing/Chip-8/disasm"
+	"io"
+	"strings"
+)
+
+//Format is the format of the lines of a trace
+type Format int
+
+const (
+	Text   Format = iota //A line per instruction with the cycle, PC, opcode, mnemonic and the registers it changed
+	NDJSON               //A JSON object per line, with the fields of Record
+)
+
+//bufferSize is the size of the records buffered by the tracer before writing them
+const bufferSize = 32 << 10
+
+//ParseFormat parses the name of a format, "text" or "ndjson"
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "text":
+		return Text, nil
+	case "ndjson":
+		return NDJSON, nil
+	}
+	return Text, fmt.Errorf("unknown trace format '%s', expected text or ndjson", s)
+}
+
+//Record is an instruction executed by the chip8
+type Record struct {
+	Cycle    uint64            `json:"cycle"`
+	PC       uint16            `json:"pc"`
+	Opcode   uint16            `json:"opcode"`
+	Mnemonic string            `json:"mnemonic"`
+	Changes  map[string]uint16 `json:"changes,omitempty"` //New values of the registers changed by the instruction
+}
+
+//Tracer writes a record of every instruction executed by a chip8 that passes its filter.
+//The records are buffered and written in blocks of whole lines, so Flush must be called after the last cycle.
+type Tracer struct {
+	w      io.Writer
+	format Format
+	filter Filter
+	c8     *chip8.Chip8
+	buf    bytes.Buffer
+	err    error
+}
+
+//New instantiates a Tracer which writes to w the records that pass filter
+func New(w io.Writer, format Format, filter Filter) *Tracer {
+	return &Tracer{w: w, format: format, filter: filter}
+}
+
+//Attach makes the tracer trace the instructions executed by c8, replacing its cycle hook
+func (t *Tracer) Attach(c8 *chip8.Chip8) {
+	t.c8 = c8
+	c8.SetCycleHook(t.trace)
+}
+
+//Close flushes the tracer and closes its writer, if it's an io.Closer
+func (t *Tracer) Close() error {
+	err := t.Flush()
+	if closer, ok := t.w.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//Flush writes the buffered records. It returns the first error writing the trace, if any.
+func (t *Tracer) Flush() error {
+	if t.err == nil && t.buf.Len() > 0 {
+		_, t.err = t.w.Write(t.buf.Bytes())
+	}
+	t.buf.Reset()
+	return t.err
+}
+
+//trace is the cycle hook of the chip8
+func (t *Tracer) trace(pc uint16, opcode uint16, before chip8.Registers) {
+	cycle := t.c8.Cycles()
+	if t.err != nil || !t.filter.Match(pc, opcode, cycle) {
+		return
+	}
+	r := Record{
+		Cycle:    cycle,
+		PC:       pc,
+		Opcode:   opcode,
+		Mnemonic: t.mnemonic(pc),
+		Changes:  changes(before, t.c8.Registers()),
+	}
+	t.write(r)
+	if t.buf.Len() >= bufferSize {
+		_ = t.Flush()
+	}
+}
+
+//write appends the record to the buffer, in the format of the tracer
+func (t *Tracer) write(r Record) {
+	if t.format == NDJSON {
+		line, _ := json.Marshal(r)
+		t.buf.Write(line)
+		t.buf.WriteByte('\n')
+		return
+	}
+	line := fmt.Sprintf("%10d  %03X  %04X  %-24s", r.Cycle, r.PC, r.Opcode, r.Mnemonic)
+	for _, name := range registerNames {
+		if value, ok := r.Changes[name]; ok {
+			line += fmt.Sprintf(" %s=%02X", name, value)
+		}
+	}
+	t.buf.WriteString(strings.TrimRight(line, " ") + "\n")
+}
+
+//mnemonic returns the assembly of the instruction at addr
+func (t *Tracer) mnemonic(addr uint16) string {
+	var memory [4]byte
+	for k := range memory {
+		memory[k] = t.c8.ReadMemory(addr + uint16(k))
+	}
+	if in, ok := disasm.Decode(memory[:]); ok {
+		return in.String()
+	}
+	return "???"
+}
+
+//registerNames are the names of the registers in the records, in the order they are written in the text format
+var registerNames = []string{"V0", "V1", "V2", "V3", "V4", "V5", "V6", "V7", "V8", "V9", "VA", "VB", "VC", "VD", "VE", "VF", "I", "SP", "DT", "ST"}
+
+//changes returns the new values of the registers that are different in before and after, or nil if none changed
+func changes(before, after chip8.Registers) map[string]uint16 {
+	var changed map[string]uint16
+	set := func(name string, old, value uint16) {
+		if old == value {
+			return
+		}
+		if changed == nil {
+			changed = map[string]uint16{}
+		}
+		changed[name] = value
+	}
+	for k := range after.V {
+		set(registerNames[k], uint16(before.V[k]), uint16(after.V[k]))
+	}
+	set("I", before.I, after.I)
+	set("SP", uint16(before.SP), uint16(after.SP))
+	set("DT", uint16(before.DelayTimer), uint16(after.DelayTimer))
+	set("ST", uint16(before.SoundTimer), uint16(after.SoundTimer))
+	return changed
+}
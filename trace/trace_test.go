@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/NoetherianRing/Chip-8/chip8"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//program loads V0 and V1, adds them and loops forever
+var program = []byte{
+	0x60, 0x05, //200: LD V0, #05
+	0x61, 0x0A, //202: LD V1, #0A
+	0x80, 0x14, //204: ADD V0, V1
+	0xA3, 0x00, //206: LD I, #300
+	0x12, 0x08, //208: JP #208
+}
+
+//run executes n cycles of program, traced with the given format and filter
+func run(t *testing.T, n int, format Format, filter Filter) string {
+	filename := filepath.Join(t.TempDir(), "program.ch8")
+	assert.NoError(t, ioutil.WriteFile(filename, program, 0644), "")
+	c8, err := chip8.NewChip8(nil, chip8.Quirks{})
+	assert.NoError(t, err, "")
+	assert.NoError(t, c8.LoadROM(filename), "")
+
+	var out bytes.Buffer
+	tracer := New(&out, format, filter)
+	tracer.Attach(c8)
+	for k := 0; k < n; k++ {
+		assert.NoError(t, c8.Cycle(), "")
+	}
+	assert.NoError(t, tracer.Flush(), "")
+	return out.String()
+}
+
+func TestTracer_Text(t *testing.T) {
+	lines := strings.Split(strings.TrimRight(run(t, 6, Text, Filter{}), "\n"), "\n")
+	assert.Equal(t, 6, len(lines), "")
+	assert.Equal(t, "         0  200  6005  LD V0, #05               V0=05", lines[0], "")
+	assert.Equal(t, "         2  204  8014  ADD V0, V1               V0=0F", lines[2], "")
+	assert.Equal(t, "         3  206  A300  LD I, #300               I=300", lines[3], "")
+	assert.Equal(t, "         5  208  1208  JP #208", lines[5], "")
+}
+
+func TestTracer_NDJSON(t *testing.T) {
+	out := run(t, 4, NDJSON, Filter{})
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	var records []Record
+	for scanner.Scan() {
+		var r Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r), scanner.Text())
+		records = append(records, r)
+	}
+	assert.Equal(t, 4, len(records), "")
+	assert.Equal(t, Record{Cycle: 1, PC: 0x202, Opcode: 0x610A, Mnemonic: "LD V1, #0A", Changes: map[string]uint16{"V1": 0xA}}, records[1], "")
+}
+
+func TestTracer_Filter(t *testing.T) {
+	classes, err := ParseClasses("8, a")
+	assert.NoError(t, err, "")
+	out := run(t, 10, Text, Filter{Classes: classes})
+	assert.Equal(t, 2, strings.Count(out, "\n"), "ADD and LD I")
+
+	pc, err := ParseRange("204-206", 16)
+	assert.NoError(t, err, "")
+	out = run(t, 10, Text, Filter{PC: pc})
+	assert.Equal(t, 2, strings.Count(out, "\n"), "ADD and LD I")
+
+	cycles, err := ParseRange("6-", 10)
+	assert.NoError(t, err, "")
+	out = run(t, 10, Text, Filter{Cycles: cycles})
+	assert.Equal(t, 4, strings.Count(out, "\n"), "cycles 6 to 9")
+
+	for _, s := range []string{"x", "206-204", "1-2-3"} {
+		_, err = ParseRange(s, 16)
+		assert.Error(t, err, s)
+	}
+	_, err = ParseClasses("10")
+	assert.Error(t, err, "")
+}
+
+func TestRotatingFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "trace.log")
+	r, err := NewRotatingFile(name, 10, 2)
+	assert.NoError(t, err, "")
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err = r.Write([]byte(line))
+		assert.NoError(t, err, "")
+	}
+	assert.NoError(t, r.Close(), "")
+
+	for file, expected := range map[string]string{name: "fourth\n", name + ".1": "third\n", name + ".2": "second\n"} {
+		content, err := ioutil.ReadFile(file)
+		assert.NoError(t, err, "")
+		assert.Equal(t, expected, string(content), file)
+	}
+	_, err = ioutil.ReadFile(name + ".3")
+	assert.Error(t, err, "only 2 old files are kept")
+
+	size, err := ParseSize("10M")
+	assert.NoError(t, err, "")
+	assert.Equal(t, int64(10<<20), size, "")
+}